@@ -33,5 +33,5 @@ func TestExample(t *testing.T) {
 	})
 
 	o, _ := swaggerizer.Swaggerize(swag, routes)
-	t.Logf(o)
+	t.Log(o)
 }
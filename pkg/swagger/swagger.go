@@ -1,5 +1,10 @@
 package swagger
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Model is the struct of the swagger spec
 type Model struct {
 	Swagger             string                        `json:"swagger"`
@@ -62,23 +67,75 @@ func (s *Model) SetDefinitions(definitions map[string]Definition) *Model {
 	return s
 }
 
-// AddPath adds PathMethods on a path's name. Supports: Get, Post, Put, Delete
+// AddPath adds PathMethods on a path's name. Operations and shared parameters already defined on the path
+// are overwritten without notice.
+//
+// Deprecated: AddPath silently drops the operations registered twice on a path. Use MergePath, which reports them.
 func (s *Model) AddPath(name string, definition PathMethods) *Model {
-	if val, ok := s.Paths[name]; ok {
-		if definition.Post != nil {
-			val.Post = definition.Post
-		} else if definition.Get != nil {
-			val.Get = definition.Get
-		} else if definition.Put != nil {
-			val.Put = definition.Put
-		} else if definition.Delete != nil {
-			val.Delete = definition.Delete
+	s.mergePath(name, definition, false)
+	return s
+}
+
+// MergePath merges PathMethods on a path's name. Supports: Get, Post, Put, Delete, Patch, Head, Options
+// and the parameters shared by the operations of the path.
+// It returns an error and leaves the path unchanged if an operation is already defined on the path, or if
+// a parameter conflicts with a shared parameter of the same name and location.
+func (s *Model) MergePath(name string, definition PathMethods) error {
+	return s.mergePath(name, definition, true)
+}
+
+func (s *Model) mergePath(name string, definition PathMethods, strict bool) error {
+	val := s.Paths[name]
+	operations := []struct {
+		verb     string
+		existing **PathItem
+		added    *PathItem
+	}{
+		{"get", &val.Get, definition.Get},
+		{"put", &val.Put, definition.Put},
+		{"post", &val.Post, definition.Post},
+		{"delete", &val.Delete, definition.Delete},
+		{"options", &val.Options, definition.Options},
+		{"head", &val.Head, definition.Head},
+		{"patch", &val.Patch, definition.Patch},
+	}
+	for _, operation := range operations {
+		if strict && operation.added != nil && *operation.existing != nil {
+			return fmt.Errorf("path %s: operation %s is already defined", name, operation.verb)
 		}
-		s.Paths[name] = val
-	} else {
-		s.Paths[name] = definition
 	}
-	return s
+
+	parameters := append([]PathItemParameter{}, val.Parameters...)
+	for _, parameter := range definition.Parameters {
+		duplicate := false
+		for i, existing := range parameters {
+			if existing.Name != parameter.Name || existing.In != parameter.In {
+				continue
+			}
+			if strict && !reflect.DeepEqual(existing, parameter) {
+				return fmt.Errorf("path %s: parameter %s in %s is already defined differently", name, parameter.Name, parameter.In)
+			}
+			parameters[i] = parameter
+			duplicate = true
+		}
+		if !duplicate {
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	for _, operation := range operations {
+		if operation.added != nil {
+			*operation.existing = operation.added
+		}
+	}
+	if len(parameters) > 0 {
+		val.Parameters = parameters
+	}
+	if s.Paths == nil {
+		s.Paths = make(map[string]PathMethods)
+	}
+	s.Paths[name] = val
+	return nil
 }
 
 func (s *Model) SetPaths(paths map[string]PathMethods) *Model {
@@ -156,36 +213,73 @@ func (pathItem *PathItem) AddParameter(parameter PathItemParameter) *PathItem {
 
 // PathMethods is a holder object used to define the swagger spec and serialize to JSON
 type PathMethods struct {
-	Post   *PathItem `json:"post,omitempty"`
-	Get    *PathItem `json:"get,omitempty"`
-	Put    *PathItem `json:"put,omitempty"`
-	Delete *PathItem `json:"delete,omitempty"`
+	Post    *PathItem `json:"post,omitempty"`
+	Get     *PathItem `json:"get,omitempty"`
+	Put     *PathItem `json:"put,omitempty"`
+	Delete  *PathItem `json:"delete,omitempty"`
+	Patch   *PathItem `json:"patch,omitempty"`
+	Head    *PathItem `json:"head,omitempty"`
+	Options *PathItem `json:"options,omitempty"`
+	// Parameters are shared by all the operations of the path
+	Parameters []PathItemParameter `json:"parameters,omitempty"`
 }
 
 // PathItemParameter is a holder object used to define the swagger spec and serialize to JSON
 type PathItemParameter struct {
-	Ref              string   `json:"$ref,omitempty"`
-	In               string   `json:"in,omitempty"` //query, header, path, formdata, or body
-	Name             string   `json:"name,omitempty"`
-	Description      string   `json:"description,omitempty"`
-	Required         bool     `json:"required,omitempty"`
-	Enum             []string `json:"enum,omitempty"`
-	Type             string   `json:"type,omitempty"`
-	Format           string   `json:"format,omitempty"`
-	Schema           *Schema  `json:"schema,omitempty"`
-	CollectionFormat string   `json:"collectionFormat,omitempty"`
+	Ref              string              `json:"$ref,omitempty"`
+	In               string              `json:"in,omitempty"` //query, header, path, formdata, or body
+	Name             string              `json:"name,omitempty"`
+	Description      string              `json:"description,omitempty"`
+	Required         bool                `json:"required,omitempty"`
+	Enum             []interface{}       `json:"enum,omitempty"`
+	Type             string              `json:"type,omitempty"`
+	Format           string              `json:"format,omitempty"`
+	Schema           *Schema             `json:"schema,omitempty"`
+	Items            *DefinitionProperty `json:"items,omitempty"` //required if type is array
+	CollectionFormat string              `json:"collectionFormat,omitempty"`
+	Maximum          *float64            `json:"maximum,omitempty"`
+	ExclusiveMaximum bool                `json:"exclusiveMaximum,omitempty"`
+	Minimum          *float64            `json:"minimum,omitempty"`
+	ExclusiveMinimum bool                `json:"exclusiveMinimum,omitempty"`
+	MaxLength        int                 `json:"maxLength,omitempty"`
+	MinLength        int                 `json:"minLength,omitempty"`
+	Pattern          string              `json:"pattern,omitempty"`
+	MaxItems         int                 `json:"maxItems,omitempty"`
+	MinItems         int                 `json:"minItems,omitempty"`
+	UniqueItems      bool                `json:"uniqueItems,omitempty"`
+	MultipleOf       float64             `json:"multipleOf,omitempty"`
+	Default          interface{}         `json:"default,omitempty"`
+	Example          interface{}         `json:"x-example,omitempty"`
+	Deprecated       bool                `json:"x-deprecated,omitempty"`
 }
 
 // Schema is a holder object used to define the swagger spec and serialize to JSON
 type Schema struct {
-	Ref              string  `json:"$ref,omitempty"`
-	Format           string  `json:"format,omitempty"`
-	Title            string  `json:"title,omitempty"`
-	Description      string  `json:"description,omitempty"`
-	Default          string  `json:"default,omitempty"`
-	Maximum          float64 `json:"maximum,omitempty"`
-	Minimum          float64 `json:"minimum,omitempty"`
-	ExclusiveMaximum bool    `json:"exclusiveMaximum,omitempty"`
+	Ref                  string            `json:"$ref,omitempty"`
+	Type                 string            `json:"type,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	Title                string            `json:"title,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Items                *Schema           `json:"items,omitempty"`
+	Default              interface{}       `json:"default,omitempty"`
+	Example              interface{}       `json:"example,omitempty"`
+	Deprecated           bool              `json:"x-deprecated,omitempty"`
+	Maximum              *float64          `json:"maximum,omitempty"`
+	ExclusiveMaximum     bool              `json:"exclusiveMaximum,omitempty"`
+	Minimum              *float64          `json:"minimum,omitempty"`
+	ExclusiveMinimum     bool              `json:"exclusiveMinimum,omitempty"`
+	MaxLength            int               `json:"maxLength,omitempty"`
+	MinLength            int               `json:"minLength,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	MaxItems             int               `json:"maxItems,omitempty"`
+	MinItems             int               `json:"minItems,omitempty"`
+	UniqueItems          bool              `json:"uniqueItems,omitempty"`
+	MultipleOf           float64           `json:"multipleOf,omitempty"`
+	Enum                 []interface{}     `json:"enum,omitempty"`
+	AdditionalProperties *Schema           `json:"additionalProperties,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	AllOf                []Schema          `json:"allOf,omitempty"`
 }
 
 // PathResponse is a holder object used to define the swagger spec and serialize to JSON
@@ -218,9 +312,13 @@ type SecurityDefinition struct {
 
 // Definition is a holder object used to define the swagger spec and serialize to JSON
 type Definition struct {
-	Type       string                        `json:"type,omitempty"`
-	Properties map[string]DefinitionProperty `json:"properties,omitempty"`
-	XML        DefinitionXML                 `json:"xml,omitempty"`
+	Type                 string                        `json:"type,omitempty"`
+	Properties           map[string]DefinitionProperty `json:"properties,omitempty"`
+	Required             []string                      `json:"required,omitempty"`
+	AdditionalProperties *DefinitionProperty           `json:"additionalProperties,omitempty"`
+	AllOf                []Schema                      `json:"allOf,omitempty"`
+	Discriminator        string                        `json:"discriminator,omitempty"`
+	XML                  DefinitionXML                 `json:"xml,omitempty"`
 }
 
 // AddProperty is used to add a property to a swagger route definition
@@ -232,25 +330,8 @@ func (definition *Definition) AddProperty(name string, prop DefinitionProperty)
 	return definition
 }
 
-// DefinitionProperty is a holder object used to define the swagger spec and serialize to JSON
-type DefinitionProperty struct {
-	Ref              string               `json:"$ref,omitempty"`
-	Type             string               `json:"type,omitempty"`
-	Format           string               `json:"format,omitempty"`
-	Items            []DefinitionProperty `json:"items,omitempty"`
-	Default          string               `json:"default,omitempty"`
-	Maximum          float32              `json:"maximum,omitempty"`
-	ExclusiveMaximum bool                 `json:"exclusiveMaximum,omitempty"`
-	Minimum          float32              `json:"minimum,omitempty"`
-	MaxLength        int                  `json:"maxLength,omitempty"`
-	MinLength        int                  `json:"minLength,omitempty"`
-	Pattern          string               `json:"pattern,omitempty"`
-	MaxItems         string               `json:"maxItems,omitempty"`
-	MinItems         string               `json:"minItems,omitempty"`
-	UniqueItems      bool                 `json:"uniqueItems,omitempty"`
-	MultipleOf       float32              `json:"multipleOf,omitempty"`
-	Enum             []string             `json:"enum,omitempty"`
-}
+// DefinitionProperty is the schema of a property on a Definition. It is an alias of Schema.
+type DefinitionProperty = Schema
 
 // DefinitionXML is a holder object used to define the swagger spec and serialize to JSON
 type DefinitionXML struct {
@@ -0,0 +1,81 @@
+package swagger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergePathOperations(t *testing.T) {
+	swag := NewSwagger("myapi.example.com", "/")
+	get := &PathItem{OperationID: "getUser"}
+	put := &PathItem{OperationID: "putUser"}
+	patch := &PathItem{OperationID: "patchUser"}
+
+	if err := swag.MergePath("/user/{username}", PathMethods{Get: get}); err != nil {
+		t.Fatal(err)
+	}
+	if err := swag.MergePath("/user/{username}", PathMethods{Put: put, Patch: patch}); err != nil {
+		t.Fatal(err)
+	}
+
+	methods := swag.Paths["/user/{username}"]
+	if methods.Get != get || methods.Put != put || methods.Patch != patch {
+		t.Errorf("expected every operation to be merged, got %+v", methods)
+	}
+}
+
+func TestMergePathDuplicateOperation(t *testing.T) {
+	swag := NewSwagger("myapi.example.com", "/")
+	get := &PathItem{OperationID: "getUser"}
+	if err := swag.MergePath("/user", PathMethods{Get: get}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := swag.MergePath("/user", PathMethods{Post: &PathItem{}, Get: &PathItem{OperationID: "otherUser"}})
+	if err == nil || !strings.Contains(err.Error(), "operation get is already defined") {
+		t.Fatalf("expected a duplicate operation error, got %v", err)
+	}
+	methods := swag.Paths["/user"]
+	if methods.Get != get || methods.Post != nil {
+		t.Errorf("expected the path to be left unchanged, got %+v", methods)
+	}
+}
+
+func TestMergePathParameters(t *testing.T) {
+	swag := NewSwagger("myapi.example.com", "/")
+	username := PathItemParameter{In: "path", Name: "username", Required: true, Type: "string"}
+	verbose := PathItemParameter{In: "query", Name: "verbose", Type: "boolean"}
+
+	if err := swag.MergePath("/user/{username}", PathMethods{Get: &PathItem{}, Parameters: []PathItemParameter{username}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := swag.MergePath("/user/{username}", PathMethods{Put: &PathItem{}, Parameters: []PathItemParameter{username, verbose}}); err != nil {
+		t.Fatal(err)
+	}
+	if params := swag.Paths["/user/{username}"].Parameters; !reflect.DeepEqual(params, []PathItemParameter{username, verbose}) {
+		t.Errorf("expected shared parameters to be merged, got %+v", params)
+	}
+
+	conflicting := PathItemParameter{In: "path", Name: "username", Required: true, Type: "integer"}
+	err := swag.MergePath("/user/{username}", PathMethods{Parameters: []PathItemParameter{conflicting}})
+	if err == nil || !strings.Contains(err.Error(), "parameter username in path") {
+		t.Errorf("expected a conflicting parameter error, got %v", err)
+	}
+}
+
+func TestAddPathReplaces(t *testing.T) {
+	swag := NewSwagger("myapi.example.com", "/")
+	get := &PathItem{OperationID: "getUser"}
+	other := &PathItem{OperationID: "otherUser"}
+	username := PathItemParameter{In: "path", Name: "username", Required: true, Type: "string"}
+	replaced := PathItemParameter{In: "path", Name: "username", Required: true, Type: "integer"}
+
+	swag.AddPath("/user/{username}", PathMethods{Get: get, Parameters: []PathItemParameter{username}}).
+		AddPath("/user/{username}", PathMethods{Get: other, Parameters: []PathItemParameter{replaced}})
+
+	methods := swag.Paths["/user/{username}"]
+	if methods.Get != other || !reflect.DeepEqual(methods.Parameters, []PathItemParameter{replaced}) {
+		t.Errorf("expected AddPath to replace the operation and the parameter, got %+v", methods)
+	}
+}
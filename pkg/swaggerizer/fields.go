@@ -0,0 +1,239 @@
+package swaggerizer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structField is a field of a struct as serialized by encoding/json.
+type structField struct {
+	reflect.StructField
+	// declaring is the struct declaring the field, which is an embedded struct for promoted fields
+	declaring reflect.Type
+	name      string
+	omitEmpty bool
+	asString  bool
+	// allOf is set on embedded structs composed using allOf instead of being promoted
+	allOf bool
+	// depth is the embedding depth of a promoted field, 0 for fields declared on the struct itself
+	depth  int
+	tagged bool
+}
+
+// structFields returns the fields of t serialized by encoding/json, in declaration order.
+// Fields of embedded structs are promoted and name conflicts are resolved the way encoding/json does:
+// the shallowest field wins, then the only json tagged field, otherwise every conflicting field is dropped.
+// Errors in the swagger tags of embedded structs are returned as a *TagError.
+func structFields(t reflect.Type) ([]structField, error) {
+	depths, err := embeddingDepths(t)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := collectFields(t, 0, depths)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	byName := make(map[string][]structField)
+	for _, field := range fields {
+		if field.allOf {
+			continue
+		}
+		if _, ok := byName[field.name]; !ok {
+			names = append(names, field.name)
+		}
+		byName[field.name] = append(byName[field.name], field)
+	}
+
+	ret := []structField{}
+	for _, field := range fields {
+		if field.allOf {
+			ret = append(ret, field)
+		}
+	}
+	for _, name := range names {
+		if field, ok := dominantField(byName[name]); ok {
+			ret = append(ret, field)
+		}
+	}
+	return ret, nil
+}
+
+// embeddingDepths returns the shallowest depth at which every struct promoted into t is embedded.
+// As in encoding/json, a struct is only promoted at that depth: embedding it deeper again is ignored,
+// while embedding it twice at that depth promotes its fields twice, so that they conflict and are dropped.
+func embeddingDepths(t reflect.Type) (map[reflect.Type]int, error) {
+	depths := map[reflect.Type]int{t: 0}
+	level := []reflect.Type{t}
+	for depth := 1; len(level) > 0; depth++ {
+		next := []reflect.Type{}
+		for _, parent := range level {
+			for i := 0; i < parent.NumField(); i++ {
+				embedded, allOf, ok, err := embeddedStruct(parent, parent.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				if !ok || allOf {
+					continue
+				}
+				if _, seen := depths[embedded]; !seen {
+					depths[embedded] = depth
+					next = append(next, embedded)
+				}
+			}
+		}
+		level = next
+	}
+	return depths, nil
+}
+
+// embeddedStruct returns the struct type embedded by field, if field is an embedded struct serialized
+// by encoding/json as part of parent, and whether it is composed using allOf instead of being promoted.
+// Embedded pointers to unexported structs are ignored, as encoding/json cannot decode them.
+func embeddedStruct(parent reflect.Type, field reflect.StructField) (embedded reflect.Type, allOf bool, ok bool, err error) {
+	if !field.Anonymous {
+		return nil, false, false, nil
+	}
+	if _, _, _, skip := parseJSONTag(field); skip || strings.Split(field.Tag.Get("json"), ",")[0] != "" {
+		return nil, false, false, nil
+	}
+	embedded = field.Type
+	if embedded.Kind() == reflect.Ptr {
+		if field.PkgPath != "" {
+			return nil, false, false, nil
+		}
+		embedded = embedded.Elem()
+	}
+	if embedded.Kind() != reflect.Struct {
+		return nil, false, false, nil
+	}
+	options, err := parseTagOptions(parent, field)
+	if err != nil {
+		return nil, false, false, err
+	}
+	return embedded, options != nil && options.AllOf, true, nil
+}
+
+func collectFields(t reflect.Type, depth int, depths map[reflect.Type]int) ([]structField, error) {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, asString, skip := parseJSONTag(field)
+		if skip {
+			continue
+		}
+		tagged := strings.Split(field.Tag.Get("json"), ",")[0] != ""
+
+		if field.Anonymous && !tagged {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				embedded, allOf, ok, err := embeddedStruct(t, field)
+				if err != nil {
+					return nil, err
+				}
+				switch {
+				case ok && allOf:
+					fields = append(fields, structField{StructField: field, declaring: t, name: name, allOf: true, depth: depth})
+				case ok && depths[embedded] == depth+1:
+					promoted, err := collectFields(embedded, depth+1, depths)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, promoted...)
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported fields are not serialized by encoding/json
+			continue
+		}
+
+		fields = append(fields, structField{
+			StructField: field,
+			declaring:   t,
+			name:        name,
+			omitEmpty:   omitEmpty,
+			asString:    asString,
+			depth:       depth,
+			tagged:      tagged,
+		})
+	}
+	return fields, nil
+}
+
+// dominantField returns the field serialized by encoding/json amongst fields sharing the same name.
+func dominantField(fields []structField) (structField, bool) {
+	depth := fields[0].depth
+	for _, field := range fields {
+		if field.depth < depth {
+			depth = field.depth
+		}
+	}
+
+	shallowest := []structField{}
+	tagged := []structField{}
+	for _, field := range fields {
+		if field.depth == depth {
+			shallowest = append(shallowest, field)
+			if field.tagged {
+				tagged = append(tagged, field)
+			}
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], true
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return structField{}, false
+}
+
+// parseJSONTag reads the encoding/json tag of a field. It returns the serialized name of the field,
+// whether the omitempty and string options are set, and whether the field is skipped by encoding/json.
+func parseJSONTag(field reflect.StructField) (name string, omitEmpty bool, asString bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false, true
+	}
+
+	name = field.Name
+	splitted := strings.Split(tag, ",")
+	if splitted[0] != "" {
+		name = splitted[0]
+	}
+	for _, option := range splitted[1:] {
+		switch option {
+		case "omitempty":
+			omitEmpty = true
+		case "string":
+			asString = true
+		}
+	}
+	return name, omitEmpty, asString, false
+}
+
+// isStringEncodable reports whether the json string option applies to t, which is the case for
+// numbers and booleans.
+func isStringEncodable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool || isNumber(t)
+}
+
+// isNumber reports whether t is an integer or a floating point number.
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package swaggerizer
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
+
+// Generator converts Routes into a Swagger 2.0 model using its own type registry.
+// Use NewGenerator to create one, or the package level functions to use the default generator.
+// The zero value is a Generator with an empty type registry.
+type Generator struct {
+	// Naming names the definitions of the reflected types. DefaultNamingStrategy is used when Naming is nil.
+	Naming NamingStrategy
+	// ValidationTags enables reading the validate and binding tags used by go-playground/validator and gin.
+	// Their rules are translated into required properties and schema keywords. The swagger tag takes precedence.
+	ValidationTags bool
+	// Warn receives the warnings raised while swaggerizing, such as types documented as free-form.
+	// Warnings are written to the standard logger when Warn is nil. Warnings about a type are raised once per
+	// Swaggerize call.
+	Warn func(warning string)
+
+	types      map[reflect.Type]swagger.Schema
+	interfaces map[reflect.Type]polymorphicType
+	inlining   map[reflect.Type]InlineMode
+
+	// err is the first registration error, returned by Swaggerize
+	err error
+}
+
+// NewGenerator creates a Generator with an empty type registry.
+func NewGenerator() *Generator {
+	return &Generator{
+		types:      make(map[reflect.Type]swagger.Schema),
+		interfaces: make(map[reflect.Type]polymorphicType),
+		inlining:   make(map[reflect.Type]InlineMode),
+	}
+}
+
+var defaultGenerator = NewGenerator()
+
+// RegisterType registers the schema used to document t on the Generator.
+// Registered types are not reflected: the schema is used as is wherever t is used,
+// whether as a property, a parameter, a request model or a response model.
+// Types are meant to be registered before calling Swaggerize.
+func (g *Generator) RegisterType(t reflect.Type, schema swagger.Schema) *Generator {
+	if g.types == nil {
+		g.types = make(map[reflect.Type]swagger.Schema)
+	}
+	g.types[t] = schema
+	return g
+}
+
+// RegisterType registers the schema used to document t on the default generator. See Generator.RegisterType.
+func RegisterType(t reflect.Type, schema swagger.Schema) {
+	defaultGenerator.RegisterType(t, schema)
+}
+
+// RegisterInterface registers the implementations of the interface type iface on the Generator.
+// Fields of type iface reference a definition named after iface, using discriminator as its discriminator property.
+// Every implementation is added as a definition composed of the iface definition using allOf, named after the
+// discriminator value identifying it, as required by Swagger 2.0. Implementations are structs, pointers to structs
+// or reflect.Types. If iface is not an interface or an implementation does not implement it, nothing is registered
+// and Swaggerize returns the error.
+func (g *Generator) RegisterInterface(iface reflect.Type, discriminator string, implementations map[string]interface{}) *Generator {
+	if iface == nil || iface.Kind() != reflect.Interface {
+		g.registrationErrorf("RegisterInterface: %v is not an interface", iface)
+		return g
+	}
+	values := []string{}
+	for value := range implementations {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	polymorphic := polymorphicType{discriminator: discriminator, implementations: make(map[string]reflect.Type)}
+	for _, value := range values {
+		implementation := implementations[value]
+		t, ok := implementation.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(implementation)
+		}
+		if t == nil || !t.Implements(iface) {
+			g.registrationErrorf("RegisterInterface: %v does not implement %s", t, iface)
+			return g
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		polymorphic.implementations[value] = t
+	}
+	if g.interfaces == nil {
+		g.interfaces = make(map[reflect.Type]polymorphicType)
+	}
+	g.interfaces[iface] = polymorphic
+	return g
+}
+
+// RegisterInterface registers the implementations of iface on the default generator. See Generator.RegisterInterface.
+func RegisterInterface(iface reflect.Type, discriminator string, implementations map[string]interface{}) {
+	defaultGenerator.RegisterInterface(iface, discriminator, implementations)
+}
+
+// SetWarn sets the function receiving the warnings of the default generator. See Generator.Warn.
+func SetWarn(warn func(warning string)) {
+	defaultGenerator.Warn = warn
+}
+
+// Swaggerize converts an array of Routes into a Swagger 2.0 model (swagger.Model) using the default generator.
+func Swaggerize(swag *swagger.Model, routes []Route) (string, error) {
+	return defaultGenerator.Swaggerize(swag, routes)
+}
+
+// registeredType returns the schema of t from the type registry, falling back to the schema provided
+// by t itself, to the well-known types and then to the schema of types marshaling themselves.
+// Pointers are documented as the type they point to, unless only the pointer marshals itself.
+func (g *Generator) registeredType(t reflect.Type, definitions *definitionSet) (swagger.Schema, bool) {
+	schema, ok, marshaler := g.lookupType(t)
+	if marshaler != nil {
+		g.warnTypef(definitions, marshaler, "%s implements json.Marshaler and is documented as free-form, register its schema to document it", marshaler)
+	}
+	return schema, ok
+}
+
+// lookupType returns the schema of t as registeredType does, without raising warnings. marshaler is the
+// type documented as free-form because it implements json.Marshaler, if any.
+func (g *Generator) lookupType(t reflect.Type) (schema swagger.Schema, ok bool, marshaler reflect.Type) {
+	if schema, ok := g.types[t]; ok {
+		return schema, true, nil
+	}
+	if t.Kind() == reflect.Ptr {
+		if schema, ok, marshaler := g.lookupType(t.Elem()); ok {
+			return schema, true, marshaler
+		}
+		return marshalerSchema(t)
+	}
+	if schema, ok := providedSchema(t); ok {
+		return schema, true, nil
+	}
+	if schema, ok := wellKnownType(t); ok {
+		return schema, true, nil
+	}
+	return marshalerSchema(t)
+}
+
+// marshalerSchema returns the schema of types implementing json.Marshaler or encoding.TextMarshaler.
+// Like encoding/json, methods with a pointer receiver are only used on pointers: a value of type T is
+// encoded as its kind even if *T implements a marshaler.
+func marshalerSchema(t reflect.Type) (schema swagger.Schema, ok bool, marshaler reflect.Type) {
+	if t.Kind() == reflect.Interface {
+		return swagger.Schema{}, false, nil
+	}
+	if t.Implements(jsonMarshalerType) {
+		return swagger.Schema{}, true, t
+	}
+	if t.Implements(textMarshalerType) {
+		return swagger.Schema{Type: "string"}, true, nil
+	}
+	return swagger.Schema{}, false, nil
+}
+
+// registrationErrorf records a registration error, unless one has already been recorded.
+func (g *Generator) registrationErrorf(format string, args ...interface{}) {
+	if g.err == nil {
+		g.err = fmt.Errorf(format, args...)
+	}
+}
+
+// warnTypef raises a warning about t, unless one has already been raised while building definitions.
+func (g *Generator) warnTypef(definitions *definitionSet, t reflect.Type, format string, args ...interface{}) {
+	if definitions.warned[t] {
+		return
+	}
+	definitions.warned[t] = true
+	g.warnf(format, args...)
+}
+
+func (g *Generator) warnf(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	if g.Warn != nil {
+		g.Warn(warning)
+		return
+	}
+	log.Printf("swaggerizer: warning: %s", warning)
+}
+
+// newDefinitionSet creates the definitionSet of a Swaggerize call. The definition names of the implementations
+// of registered interfaces are reserved, as Swagger 2.0 requires them to match their discriminator value.
+func (g *Generator) newDefinitionSet() *definitionSet {
+	naming := g.Naming
+	if naming == nil {
+		naming = DefaultNamingStrategy{}
+	}
+	definitions := newDefinitionSet(naming)
+
+	ifaces := []reflect.Type{}
+	for iface := range g.interfaces {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].String() < ifaces[j].String() })
+
+	for _, iface := range ifaces {
+		for value, implementation := range g.interfaces[iface].implementations {
+			if t, taken := definitions.types[value]; taken && t != implementation {
+				g.warnf("discriminator value %s of %s is used by both %s and %s", value, iface, t, implementation)
+				continue
+			}
+			definitions.names[implementation] = value
+			definitions.types[value] = implementation
+		}
+	}
+	return definitions
+}
@@ -0,0 +1,217 @@
+package swaggerizer
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
+
+// InlineMode controls whether the schema of a struct is referenced as a definition or inlined where it is used.
+type InlineMode string
+
+const (
+	// InlineNever references the definition using $ref. This is the default.
+	InlineNever InlineMode = "never"
+	// InlineAlways inlines the schema wherever it is used.
+	InlineAlways InlineMode = "always"
+	// InlineOnce inlines the schema if it is used only once, and references the definition otherwise.
+	InlineOnce InlineMode = "once"
+)
+
+// SetInlineMode sets whether the schema of the struct type t is inlined wherever it is used.
+// The inline swagger tag of a field takes precedence over it.
+func (g *Generator) SetInlineMode(t reflect.Type, mode InlineMode) *Generator {
+	if g.inlining == nil {
+		g.inlining = make(map[reflect.Type]InlineMode)
+	}
+	g.inlining[t] = mode
+	return g
+}
+
+// SetInlineMode sets the inline mode of t on the default generator. See Generator.SetInlineMode.
+func SetInlineMode(t reflect.Type, mode InlineMode) {
+	defaultGenerator.SetInlineMode(t, mode)
+}
+
+// inliner replaces the references to the definitions to inline by their schema.
+type inliner struct {
+	generator   *Generator
+	definitions *definitionSet
+	counts      map[string]int
+	// inlined holds the names of the definitions inlined at least once
+	inlined map[string]bool
+}
+
+// inlineDefinitions inlines definitions in the other definitions and in the schemas of operations, then removes
+// the inlined definitions that are no longer referenced. Recursive and polymorphic definitions are never inlined.
+func (g *Generator) inlineDefinitions(definitions *definitionSet, operations []*swagger.PathItem) {
+	in := &inliner{
+		generator:   g,
+		definitions: definitions,
+		counts:      countRefs(definitions.definitions, operations),
+		inlined:     make(map[string]bool),
+	}
+
+	inlinedDefinitions := make(map[string]swagger.Definition)
+	for name, definition := range definitions.definitions {
+		inlinedDefinitions[name] = in.definition(name, definition)
+	}
+	definitions.definitions = inlinedDefinitions
+
+	for _, operation := range operations {
+		for i, parameter := range operation.Parameters {
+			if parameter.Schema != nil {
+				schema := in.schema(*parameter.Schema, "", "", nil)
+				operation.Parameters[i].Schema = &schema
+			}
+		}
+		for code, response := range operation.Responses {
+			response.Schema = in.schema(response.Schema, "", "", nil)
+			operation.Responses[code] = response
+		}
+	}
+
+	for removed := true; removed; {
+		removed = false
+		counts := countRefs(definitions.definitions, operations)
+		for name := range in.inlined {
+			if _, ok := definitions.definitions[name]; ok && counts[name] == 0 {
+				delete(definitions.definitions, name)
+				removed = true
+			}
+		}
+	}
+}
+
+func (in *inliner) definition(name string, definition swagger.Definition) swagger.Definition {
+	stack := []string{name}
+	definition.Properties = in.properties(definition.Properties, name, stack)
+	definition.AllOf = in.schemas(definition.AllOf, name, stack)
+	if definition.AdditionalProperties != nil {
+		additional := in.schema(*definition.AdditionalProperties, "", name, stack)
+		definition.AdditionalProperties = &additional
+	}
+	return definition
+}
+
+// schema inlines the references of schema. mode is the inline mode of the field the schema documents, and owner
+// the name of the definition declaring the properties of schema. stack holds the definitions being inlined.
+func (in *inliner) schema(schema swagger.Schema, mode InlineMode, owner string, stack []string) swagger.Schema {
+	if name := refName(schema.Ref); name != "" {
+		if !in.shouldInline(name, mode, stack) {
+			return schema
+		}
+		in.inlined[name] = true
+		inlined := definitionSchema(in.definitions.definitions[name])
+		return in.schema(inlined, "", name, append(stack, name))
+	}
+
+	if schema.Items != nil {
+		items := in.schema(*schema.Items, mode, owner, stack)
+		schema.Items = &items
+	}
+	if schema.AdditionalProperties != nil {
+		additional := in.schema(*schema.AdditionalProperties, mode, owner, stack)
+		schema.AdditionalProperties = &additional
+	}
+	schema.Properties = in.properties(schema.Properties, owner, stack)
+	schema.AllOf = in.schemas(schema.AllOf, owner, stack)
+	return schema
+}
+
+func (in *inliner) properties(properties map[string]swagger.Schema, owner string, stack []string) map[string]swagger.Schema {
+	if properties == nil {
+		return nil
+	}
+	ret := make(map[string]swagger.Schema)
+	for name, property := range properties {
+		ret[name] = in.schema(property, in.definitions.fieldInlining[owner][name], owner, stack)
+	}
+	return ret
+}
+
+func (in *inliner) schemas(schemas []swagger.Schema, owner string, stack []string) []swagger.Schema {
+	if schemas == nil {
+		return nil
+	}
+	ret := []swagger.Schema{}
+	for _, schema := range schemas {
+		ret = append(ret, in.schema(schema, "", owner, stack))
+	}
+	return ret
+}
+
+func (in *inliner) shouldInline(name string, mode InlineMode, stack []string) bool {
+	if _, ok := in.definitions.definitions[name]; !ok || in.definitions.polymorphic[name] {
+		return false
+	}
+	for _, inlining := range stack {
+		if inlining == name {
+			return false
+		}
+	}
+	if mode == "" {
+		mode = in.generator.inlining[in.definitions.types[name]]
+	}
+	switch mode {
+	case InlineAlways:
+		return true
+	case InlineOnce:
+		return in.counts[name] == 1
+	}
+	return false
+}
+
+// refName returns the name of the definition referenced by ref.
+func refName(ref string) string {
+	if !strings.HasPrefix(ref, "#/definitions/") {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
+
+// countRefs counts the references to every definition from definitions and the schemas of operations.
+func countRefs(definitions map[string]swagger.Definition, operations []*swagger.PathItem) map[string]int {
+	counts := make(map[string]int)
+	for _, definition := range definitions {
+		for _, property := range definition.Properties {
+			countSchemaRefs(property, counts)
+		}
+		for _, schema := range definition.AllOf {
+			countSchemaRefs(schema, counts)
+		}
+		if definition.AdditionalProperties != nil {
+			countSchemaRefs(*definition.AdditionalProperties, counts)
+		}
+	}
+	for _, operation := range operations {
+		for _, parameter := range operation.Parameters {
+			if parameter.Schema != nil {
+				countSchemaRefs(*parameter.Schema, counts)
+			}
+		}
+		for _, response := range operation.Responses {
+			countSchemaRefs(response.Schema, counts)
+		}
+	}
+	return counts
+}
+
+func countSchemaRefs(schema swagger.Schema, counts map[string]int) {
+	if name := refName(schema.Ref); name != "" {
+		counts[name]++
+	}
+	if schema.Items != nil {
+		countSchemaRefs(*schema.Items, counts)
+	}
+	if schema.AdditionalProperties != nil {
+		countSchemaRefs(*schema.AdditionalProperties, counts)
+	}
+	for _, property := range schema.Properties {
+		countSchemaRefs(property, counts)
+	}
+	for _, composed := range schema.AllOf {
+		countSchemaRefs(composed, counts)
+	}
+}
@@ -1,23 +1,78 @@
 package swaggerizer
 
-import "github.com/erikperez/go-swaggerize/pkg/swagger"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
 
 // Route is a holder object used to define a Swagger Route Definition
 type Route struct {
 	Group     string
 	Route     string
 	Verb      string
-	Model     interface{}
+	Model     interface{} // A struct, a pointer to a struct or a reflect.Type of a struct
+	Body      BodyMode    // Whether Model is documented as the request body, as parameters, or both
 	Responses []Response
 	Produces  []string
 	Consumes  []string
 }
 
+// RouteError reports a route that could not be swaggerized. Err is the cause, such as a *TagError.
+type RouteError struct {
+	// Verb and Route identify the route
+	Verb  string
+	Route string
+	// Response is the name of the response whose model is invalid, empty if the error is not specific to a response
+	Response string
+	Err      error
+}
+
+func (e *RouteError) Error() string {
+	if e.Response == "" {
+		return fmt.Sprintf("%s %s: %v", strings.ToUpper(e.Verb), e.Route, e.Err)
+	}
+	return fmt.Sprintf("%s %s: response %s: %v", strings.ToUpper(e.Verb), e.Route, e.Response, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// routeError names route in err, wrapping err in a *RouteError unless it already is one.
+func routeError(route Route, err error) error {
+	routeErr, ok := err.(*RouteError)
+	if !ok {
+		routeErr = &RouteError{Err: err}
+	}
+	routeErr.Verb = route.Verb
+	routeErr.Route = route.Route
+	return routeErr
+}
+
+// BodyMode tells whether the Model of a Route is documented as the request body, as parameters, or both.
+type BodyMode string
+
+const (
+	// BodyDefault documents the body and the parameters of the model for POST, PUT and PATCH requests,
+	// and only its parameters for other verbs
+	BodyDefault BodyMode = ""
+	// BodyAndParameters documents the fields of the model tagged with in as parameters and its other fields as the body
+	BodyAndParameters BodyMode = "both"
+	// BodyOnly documents every field of the model as a property of the body, including the fields tagged with in
+	BodyOnly BodyMode = "body"
+	// ParametersOnly documents the fields of the model tagged with in, the request has no body
+	ParametersOnly BodyMode = "parameters"
+)
+
 // Response is a holder object used to define a response object for a request and/or route
 type Response struct {
 	Name        string
 	Description string
-	Model       interface{}
+	Model       interface{} // A struct, a pointer to a struct or a reflect.Type of a struct
 	Headers     []ResponseHeader
 }
 
@@ -33,20 +88,98 @@ type ResponseHeader struct {
 type options struct {
 	Name             string
 	Required         bool
+	RequiredSet      bool
 	In               string
 	CollectionFormat string
 	Enum             []string
+	AllOf            bool
+	Inline           InlineMode
+	Format           string
+	Maximum          *float64
+	ExclusiveMaximum bool
+	Minimum          *float64
+	ExclusiveMinimum bool
+	MaxLength        int
+	MinLength        int
+	Pattern          string
+	MaxItems         int
+	MinItems         int
+	UniqueItems      bool
+	MultipleOf       float64
+	Description      string
+	Title            string
+	Example          string
+	ExampleSet       bool
+	Default          string
+	DefaultSet       bool
+	Deprecated       bool
+	DeprecatedSet    bool
+	// Unknown holds the keys of the tag that are not supported, which are ignored
+	Unknown []string
 }
 
 // RouteDefinition is an internal struct used to parse a route definition
 type routeDefinition struct {
-	ModelName  *string
-	Definition *swagger.Definition
-	Params     []swagger.PathItemParameter
+	// Schema references the definition of the model, or holds the schema of registered types
+	Schema *swagger.Schema
+	Params []swagger.PathItemParameter
+}
+
+// fieldSelection tells which fields of a struct are reflected, and whether those tagged with in are parameters.
+type fieldSelection int
+
+const (
+	// allFields reflects every field as a property, whether it is tagged with in or not
+	allFields fieldSelection = iota
+	// bodyFields reflects the fields tagged with in as parameters and the other fields as properties
+	bodyFields
+	// taggedFields only reflects the fields tagged with in, as parameters
+	taggedFields
+	// parameterFields only reflects the fields tagged with in other than in:body, as parameters
+	parameterFields
+)
+
+// polymorphicType holds the implementations of a registered interface by discriminator value.
+type polymorphicType struct {
+	discriminator   string
+	implementations map[string]reflect.Type
+}
+
+// definitionSet collects the definitions of the models of a Swaggerize call while they are reflected.
+type definitionSet struct {
+	definitions map[string]swagger.Definition
+	naming      NamingStrategy
+	// names and types map the types reflected to their definition names and back
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+	// candidates holds the types of the models proposing each candidate name, see scanNames
+	candidates map[string][]reflect.Type
+	// documented holds the types documented with all of their fields, rather than split into a request
+	// body and parameters, see scanNames
+	documented map[reflect.Type]bool
+	// reflected holds the types whose definition is built or being built
+	reflected map[reflect.Type]bool
+	// polymorphic holds the names of the definitions of registered interfaces and their implementations
+	polymorphic map[string]bool
+	// fieldInlining holds the inline mode of properties by definition and property name
+	fieldInlining map[string]map[string]InlineMode
+	// context is the chain of definition and property names leading to the type being reflected
+	context []string
+	// warned holds the types a warning has been raised about, so that it is raised once
+	warned map[reflect.Type]bool
 }
 
-// ResponseDefinition is an internal struct used to parse a route definition's response.
-type responseDefinition struct {
-	Definition *swagger.Definition
-	ModelName  *string
+func newDefinitionSet(naming NamingStrategy) *definitionSet {
+	return &definitionSet{
+		definitions:   make(map[string]swagger.Definition),
+		naming:        naming,
+		names:         make(map[reflect.Type]string),
+		types:         make(map[string]reflect.Type),
+		candidates:    make(map[string][]reflect.Type),
+		documented:    make(map[reflect.Type]bool),
+		reflected:     make(map[reflect.Type]bool),
+		polymorphic:   make(map[string]bool),
+		fieldInlining: make(map[string]map[string]InlineMode),
+		warned:        make(map[reflect.Type]bool),
+	}
 }
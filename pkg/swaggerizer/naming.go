@@ -0,0 +1,209 @@
+package swaggerizer
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NamingStrategy names the definitions of the types reflected by a Generator.
+type NamingStrategy interface {
+	// DefinitionNames returns the candidate names of the definition of t, from the preferred one to the
+	// most qualified one. The first candidate that is not used by another type is used.
+	// context is the chain of definition and property names leading to t, used to name anonymous structs.
+	DefinitionNames(t reflect.Type, context []string) []string
+}
+
+// DefaultNamingStrategy names definitions after the short name of their type, qualified by the package name
+// and then by the package path on collision. Generic instantiations are sanitized, for example Page[Order],
+// and anonymous structs are named after the definition and property they are declared on.
+type DefaultNamingStrategy struct{}
+
+var (
+	packagePathPattern      = regexp.MustCompile(`[\w.\-~]+/`)
+	packageQualifierPattern = regexp.MustCompile(`\w+\.`)
+	invalidNamePattern      = regexp.MustCompile(`[^\w.\-]+`)
+	underscoresPattern      = regexp.MustCompile(`__+`)
+)
+
+// DefinitionNames implements NamingStrategy.
+func (DefaultNamingStrategy) DefinitionNames(t reflect.Type, context []string) []string {
+	if t.Name() == "" {
+		return []string{sanitizeName(strings.Join(context, "_"))}
+	}
+	return qualifiedNames(t.Name(), t.PkgPath())
+}
+
+// qualifiedNames returns the short, package qualified and package path qualified names of a type.
+func qualifiedNames(name string, pkgPath string) []string {
+	// type arguments of generic instantiations are named by their package path
+	name = packagePathPattern.ReplaceAllString(name, "")
+	short := sanitizeName(packageQualifierPattern.ReplaceAllString(name, ""))
+	if pkgPath == "" {
+		return []string{short}
+	}
+	return []string{
+		short,
+		sanitizeName(path.Base(pkgPath) + "." + name),
+		sanitizeName(strings.Replace(pkgPath, "/", ".", -1) + "." + name),
+	}
+}
+
+// sanitizeName replaces the characters that are not valid in a $ref by underscores.
+func sanitizeName(name string) string {
+	name = invalidNamePattern.ReplaceAllString(name, "_")
+	name = underscoresPattern.ReplaceAllString(name, "_")
+	return strings.Trim(name, "_")
+}
+
+// scanNames collects the candidate names of the named types used by the models of routes before they are
+// reflected, so that every type of a set sharing a name is qualified whatever the order of the routes.
+// It also collects the types documented with all of their fields: every type but request models, unless
+// their route documents them as the body only.
+func (g *Generator) scanNames(routes []Route, definitions *definitionSet) {
+	seen := make(map[scannedType]bool)
+	for _, route := range routes {
+		verb := strings.ToLower(route.Verb)
+		context := []string{verb, route.Route, "body"}
+		switch {
+		case route.Body == BodyOnly:
+			g.scanModel(route.Model, context, true, seen, definitions)
+		case route.Body == ParametersOnly || route.Body == BodyDefault && !verbsWithBody[verb]:
+			// the body of the model is not documented
+		default:
+			g.scanModel(route.Model, context, false, seen, definitions)
+		}
+		for _, response := range route.Responses {
+			context := []string{strings.ToLower(route.Verb), route.Route, "response", response.Name}
+			g.scanModel(response.Model, context, true, seen, definitions)
+		}
+	}
+}
+
+// scannedType is a type scanned by scanNames, and whether it is documented with all of its fields.
+type scannedType struct {
+	t          reflect.Type
+	documented bool
+}
+
+func (g *Generator) scanModel(v interface{}, context []string, documented bool, seen map[scannedType]bool, definitions *definitionSet) {
+	if v == nil {
+		return
+	}
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	g.scanType(t, context, documented, seen, definitions)
+}
+
+// scanType collects the candidate names of t and of the types it is made of. Malformed models are
+// skipped, their errors are returned when they are reflected.
+func (g *Generator) scanType(t reflect.Type, context []string, documented bool, seen map[scannedType]bool, definitions *definitionSet) {
+	if t == nil || seen[scannedType{t, documented}] {
+		return
+	}
+	seen[scannedType{t, documented}] = true
+	if _, registered, _ := g.lookupType(t); registered {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		g.scanType(t.Elem(), context, documented, seen, definitions)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		g.scanType(t.Elem(), context, true, seen, definitions)
+	case reflect.Interface:
+		polymorphic, ok := g.interfaces[t]
+		if !ok {
+			return
+		}
+		definitions.addCandidates(t, context)
+		for _, implementation := range polymorphic.implementations {
+			g.scanType(implementation, context, true, seen, definitions)
+		}
+	case reflect.Struct:
+		if documented {
+			definitions.documented[t] = true
+		}
+		if t.Name() != "" && definitions.names[t] == "" && (documented || hasBodyFields(t)) {
+			definitions.addCandidates(t, context)
+		}
+		fields, err := structFields(t)
+		if err != nil {
+			return
+		}
+		for _, field := range fields {
+			g.scanType(field.Type, context, true, seen, definitions)
+		}
+	}
+}
+
+func (s *definitionSet) addCandidates(t reflect.Type, context []string) {
+	for _, candidate := range s.naming.DefinitionNames(t, context) {
+		s.candidates[candidate] = append(s.candidates[candidate], t)
+	}
+}
+
+// name returns the definition name of t, choosing an unused one using the naming strategy on first use.
+// Candidates proposed by another type used by the models are skipped. If every candidate is shared, as with
+// types declared in functions of the same package, the last one is used and suffixed with a number.
+func (s *definitionSet) name(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	candidates := s.naming.DefinitionNames(t, s.context)
+	if len(candidates) == 0 {
+		candidates = []string{"Definition"}
+	}
+	name := ""
+	for _, candidate := range candidates {
+		if _, taken := s.types[candidate]; !taken && !s.shared(candidate, t) {
+			name = candidate
+			break
+		}
+	}
+	if _, taken := s.types[candidates[len(candidates)-1]]; name == "" && !taken {
+		name = candidates[len(candidates)-1]
+	}
+	for i := 2; name == ""; i++ {
+		candidate := candidates[len(candidates)-1] + "_" + strconv.Itoa(i)
+		if _, taken := s.types[candidate]; !taken {
+			name = candidate
+		}
+	}
+
+	s.names[t] = name
+	s.types[name] = t
+	return name
+}
+
+// hasBodyFields reports whether the request model t has a body definition, so that it is given a name.
+func hasBodyFields(t reflect.Type) bool {
+	properties, bodyField, parameters, err := requestFields(t)
+	return err == nil && (properties || !bodyField && !parameters)
+}
+
+// candidateName returns the name t would be given, without reserving it.
+func (s *definitionSet) candidateName(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	if candidates := s.naming.DefinitionNames(t, s.context); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return "Definition"
+}
+
+// shared reports whether candidate is proposed by a type other than t.
+func (s *definitionSet) shared(candidate string, t reflect.Type) bool {
+	for _, other := range s.candidates[candidate] {
+		if other != t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package swaggerizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
+
+// pathVariables returns the names of the variables of a path template such as /user/{username}, in order.
+func pathVariables(route string) ([]string, error) {
+	variables := []string{}
+	seen := make(map[string]bool)
+	for rest := route; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("path template %s has an unmatched '}'", route)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] == '{' {
+			return nil, fmt.Errorf("path template %s has an unterminated '{'", route)
+		}
+		name := rest[open+1 : open+1+end]
+		if name == "" {
+			return nil, fmt.Errorf("path template %s has an empty variable", route)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("path template %s declares {%s} twice", route, name)
+		}
+		seen[name] = true
+		variables = append(variables, name)
+		rest = rest[open+1+end+1:]
+	}
+	return variables, nil
+}
+
+// checkPathParameters cross-checks the path parameters of an operation with the variables of its path
+// template. Path parameters must be required and match a variable. Variables without a parameter are
+// documented as required string parameters.
+func checkPathParameters(route string, params []swagger.PathItemParameter) ([]swagger.PathItemParameter, error) {
+	variables, err := pathVariables(route)
+	if err != nil {
+		return nil, err
+	}
+	declared := make(map[string]bool)
+	for _, variable := range variables {
+		declared[variable] = true
+	}
+
+	documented := make(map[string]bool)
+	for _, param := range params {
+		if param.In != "path" {
+			continue
+		}
+		if !declared[param.Name] {
+			return nil, fmt.Errorf("path parameter %s has no {%s} variable in the path template", param.Name, param.Name)
+		}
+		if !param.Required {
+			return nil, fmt.Errorf("path parameter %s must be required", param.Name)
+		}
+		documented[param.Name] = true
+	}
+
+	for _, variable := range variables {
+		if !documented[variable] {
+			params = append(params, swagger.PathItemParameter{
+				In:       "path",
+				Name:     variable,
+				Required: true,
+				Type:     "string",
+			})
+		}
+	}
+	return params, nil
+}
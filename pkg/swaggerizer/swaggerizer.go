@@ -2,23 +2,45 @@ package swaggerizer
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/erikperez/go-swaggerize/pkg/swagger"
 )
 
 // Swaggerize converts an array of Routes into a Swagger 2.0 model (swagger.Model)
-func Swaggerize(swag *swagger.Model, routes []Route) (string, error) {
+// If an error is returned, swag is left unchanged.
+func (g *Generator) Swaggerize(swag *swagger.Model, routes []Route) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	definitions := g.newDefinitionSet()
+	g.scanNames(routes, definitions)
+	operations := []*swagger.PathItem{}
+	// paths and tags are merged into swag once every route has been swaggerized
+	paths := &swagger.Model{Paths: make(map[string]swagger.PathMethods)}
+	for name, methods := range swag.Paths {
+		paths.Paths[name] = methods
+	}
+	tags := []swagger.Tag{}
 	for _, route := range routes {
 		routeVerb := strings.ToLower(route.Verb)
-		routeDefinition := parseStructToDefinition(route.Model)
+		if !supportedVerbs[routeVerb] {
+			return "", routeError(route, fmt.Errorf("unsupported verb %q, expected one of get, put, post, delete, options, head or patch", route.Verb))
+		}
+		definitions.context = []string{routeVerb, route.Route, "body"}
+		routeDefinition, err := g.parseRequestModel(route, routeVerb, definitions)
+		if err != nil {
+			return "", routeError(route, err)
+		}
+		hasModel := routeDefinition.Schema != nil
 		hasParams := len(routeDefinition.Params) > 0
-		hasModel := routeDefinition.ModelName != nil && routeDefinition.Definition != nil
 
 		if route.Group != "" {
-			swag.AddTag(swagger.Tag{Name: route.Group})
+			tags = append(tags, swagger.Tag{Name: route.Group})
 		}
 
 		if len(route.Produces) == 0 {
@@ -36,68 +58,82 @@ func Swaggerize(swag *swagger.Model, routes []Route) (string, error) {
 			Parameters: []swagger.PathItemParameter{},
 		}
 
-		responses, responseDefinitions := parseResponses(route.Responses)
-		genericMethod.Responses = responses
-		for _, responseDefinition := range responseDefinitions {
-			swag.AddDefinition(*responseDefinition.ModelName, *responseDefinition.Definition)
+		responses, err := g.parseResponses(route, definitions)
+		if err != nil {
+			return "", routeError(route, err)
 		}
+		genericMethod.Responses = responses
+		operations = append(operations, genericMethod)
 
 		if hasModel {
-			swag.AddDefinition(*routeDefinition.ModelName, *routeDefinition.Definition)
+			genericMethod.Produces = route.Produces
+			genericMethod.Consumes = route.Consumes
 
-			if routeVerb != "get" {
-				genericMethod.Produces = route.Produces
-				genericMethod.Consumes = route.Consumes
-
-				genericMethod.AddParameter(swagger.PathItemParameter{
-					In:       "body",
-					Name:     "body",
-					Required: true,
-					Schema:   &swagger.Schema{Ref: "#/definitions/" + *routeDefinition.ModelName},
-				})
-			}
+			genericMethod.AddParameter(swagger.PathItemParameter{
+				In:       "body",
+				Name:     "body",
+				Required: true,
+				Schema:   routeDefinition.Schema,
+			})
 		}
 
 		if hasParams {
 			for i := 0; i < len(routeDefinition.Params); i++ {
-				genericMethod.AddParameter(swagger.PathItemParameter{
-					In:       routeDefinition.Params[i].In,
-					Name:     routeDefinition.Params[i].Name,
-					Required: routeDefinition.Params[i].Required,
-					Type:     routeDefinition.Params[i].Type,
-					Enum:     routeDefinition.Params[i].Enum,
-				})
+				genericMethod.AddParameter(routeDefinition.Params[i])
 			}
 		}
+		genericMethod.Parameters, err = checkPathParameters(route.Route, genericMethod.Parameters)
+		if err != nil {
+			return "", routeError(route, err)
+		}
 
-		var postMethod *swagger.PathItem
-		var getMethod *swagger.PathItem
-		var putMethod *swagger.PathItem
-		var deleteMethod *swagger.PathItem
-
+		swaggerPathMethods := swagger.PathMethods{}
 		switch routeVerb {
 		case "get":
-			getMethod = genericMethod
+			swaggerPathMethods.Get = genericMethod
 			break
 		case "post":
-			postMethod = genericMethod
+			swaggerPathMethods.Post = genericMethod
 			break
 		case "delete":
-			deleteMethod = genericMethod
+			swaggerPathMethods.Delete = genericMethod
 			break
 		case "put":
-			putMethod = genericMethod
+			swaggerPathMethods.Put = genericMethod
+			break
+		case "patch":
+			swaggerPathMethods.Patch = genericMethod
+			break
+		case "head":
+			swaggerPathMethods.Head = genericMethod
+			break
+		case "options":
+			swaggerPathMethods.Options = genericMethod
 			break
 		}
-
-		swaggerPathMethods := swagger.PathMethods{
-			Post:   postMethod,
-			Put:    putMethod,
-			Delete: deleteMethod,
-			Get:    getMethod,
+		if err := paths.MergePath(route.Route, swaggerPathMethods); err != nil {
+			return "", routeError(route, err)
+		}
+	}
+	g.inlineDefinitions(definitions, operations)
+	names := []string{}
+	for name := range definitions.definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if existing, ok := swag.Definitions[name]; ok && !reflect.DeepEqual(existing, definitions.definitions[name]) {
+			return "", fmt.Errorf("definition %s is already defined differently in the model", name)
 		}
-		swag.AddPath(route.Route, swaggerPathMethods)
 	}
+	swag.SetPaths(paths.Paths)
+	for _, tag := range tags {
+		swag.AddTag(tag)
+	}
+	for _, name := range names {
+		swag.AddDefinition(name, definitions.definitions[name])
+	}
+
 	out, err := json.Marshal(swag)
 	if err != nil {
 		return "", err
@@ -106,9 +142,54 @@ func Swaggerize(swag *swagger.Model, routes []Route) (string, error) {
 	return string(out), nil
 }
 
-func parseResponses(responses []Response) (map[string]swagger.PathResponse, []responseDefinition) {
+// supportedVerbs are the verbs of the operations defined by Swagger 2.0.
+var supportedVerbs = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true,
+}
+
+// verbsWithBody are the verbs whose requests have a body by default.
+var verbsWithBody = map[string]bool{"post": true, "put": true, "patch": true}
+
+// requestParts resolves whether the body and the parameters of a route model are documented, following
+// route.Body. hasBody and hasParams tell whether the model has body fields and fields tagged with in.
+// Models that do not match the body mode or the verb of the route raise warnings.
+func (g *Generator) requestParts(route Route, verb string, hasBody bool, hasParams bool) (body bool, params bool, err error) {
+	operation := strings.ToUpper(verb) + " " + route.Route
+
+	switch route.Body {
+	case BodyDefault:
+		if hasBody && !verbsWithBody[verb] {
+			g.warnf("%s: the body of the model is ignored as %s requests have no body by default, set Route.Body to document it", operation, strings.ToUpper(verb))
+			return false, hasParams, nil
+		}
+		return hasBody, hasParams, nil
+	case ParametersOnly:
+		if hasBody {
+			g.warnf("%s: the body of the model is ignored as Route.Body is %s", operation, route.Body)
+		}
+		return false, hasParams, nil
+	case BodyOnly:
+		if hasParams {
+			g.warnf("%s: the fields tagged with in are documented as properties of the body as Route.Body is %s", operation, route.Body)
+		}
+		hasBody = hasBody || hasParams
+		hasParams = false
+	case BodyAndParameters:
+	default:
+		return false, false, fmt.Errorf("unknown body mode %q", route.Body)
+	}
+
+	if !hasBody {
+		g.warnf("%s: Route.Body is %s but the model has no body", operation, route.Body)
+	} else if !verbsWithBody[verb] {
+		g.warnf("%s: %s requests have no defined body semantics, some clients and servers drop the body", operation, strings.ToUpper(verb))
+	}
+	return hasBody, hasParams, nil
+}
+
+func (g *Generator) parseResponses(route Route, definitions *definitionSet) (map[string]swagger.PathResponse, error) {
 	ret := make(map[string]swagger.PathResponse)
-	definitions := []responseDefinition{}
+	responses := route.Responses
 	if len(responses) == 0 {
 		ret["default"] = swagger.PathResponse{Description: "Default response"}
 	} else {
@@ -116,147 +197,604 @@ func parseResponses(responses []Response) (map[string]swagger.PathResponse, []re
 			response := responses[i]
 			resp := swagger.PathResponse{Description: response.Description}
 			if response.Model != nil {
-				m := parseStructToDefinition(response.Model)
-				if m.Definition != nil {
-					definitions = append(definitions, responseDefinition{
-						Definition: m.Definition,
-						ModelName:  m.ModelName,
-					})
-					resp.Schema = swagger.Schema{Ref: "#/definitions/" + *m.ModelName}
+				definitions.context = []string{strings.ToLower(route.Verb), route.Route, "response", response.Name}
+				m, err := g.parseStructToDefinition(response.Model, definitions)
+				if err != nil {
+					return nil, &RouteError{Response: response.Name, Err: err}
+				}
+				if m.Schema != nil {
+					resp.Schema = *m.Schema
 				}
 			}
 			ret[response.Name] = resp
 		}
 	}
-	return ret, definitions
+	return ret, nil
+}
+
+// parseStructToDefinition reflects v into a definition added to definitions, along with the definitions
+// of its nested structs. The returned routeDefinition.Schema references it.
+// v may be a struct, a pointer to a struct (nil pointers included) or a reflect.Type.
+// Registered types are returned as routeDefinition.Schema instead.
+// Every field is a property of the definition, whether it is tagged with `in` or not.
+func (g *Generator) parseStructToDefinition(v interface{}, definitions *definitionSet) (routeDefinition, error) {
+	t, err := g.modelType(v)
+	if err != nil || t == nil {
+		return routeDefinition{}, err
+	}
+	if schema, ok := g.registeredType(t, definitions); ok {
+		return routeDefinition{Schema: &schema}, nil
+	}
+	if t.Kind() == reflect.Interface {
+		schema, err := g.parseInterfaceType(t, definitions)
+		if err != nil {
+			return routeDefinition{}, err
+		}
+		return routeDefinition{Schema: &schema}, nil
+	}
+	name, err := g.parseStructType(t, definitions)
+	if err != nil {
+		return routeDefinition{}, err
+	}
+	return routeDefinition{Schema: &swagger.Schema{Ref: "#/definitions/" + name}}, nil
+}
+
+// parseRequestModel reflects the request model of route, documented as the body, parameters or both as
+// resolved by requestParts. Fields tagged with `in` are returned as routeDefinition.Params and the other
+// fields make the body, returned as routeDefinition.Schema. If a field is tagged `in:body`, its schema is the
+// body. With BodyOnly, every field is a property of the body. Bodies that are not documented are not reflected.
+func (g *Generator) parseRequestModel(route Route, verb string, definitions *definitionSet) (routeDefinition, error) {
+	t, err := g.modelType(route.Model)
+	if err != nil {
+		return routeDefinition{}, err
+	}
+	if t == nil {
+		_, _, err := g.requestParts(route, verb, false, false)
+		return routeDefinition{}, err
+	}
+	if _, ok := g.registeredType(t, definitions); ok || t.Kind() == reflect.Interface {
+		if body, _, err := g.requestParts(route, verb, true, false); err != nil || !body {
+			return routeDefinition{}, err
+		}
+		return g.parseStructToDefinition(t, definitions)
+	}
+
+	properties, bodyField, parameters, err := requestFields(t)
+	if err != nil {
+		return routeDefinition{}, err
+	}
+	body, params, err := g.requestParts(route, verb, properties || bodyField, parameters)
+	if err != nil {
+		return routeDefinition{}, err
+	}
+
+	var ret routeDefinition
+	switch {
+	case body && (route.Body == BodyOnly || !bodyField && !parameters):
+		return g.parseStructToDefinition(t, definitions)
+	case body && properties:
+		ret, err = g.splitRequestModel(t, definitions, bodyFields)
+	case body:
+		ret, err = g.splitRequestModel(t, definitions, taggedFields)
+	case params:
+		ret, err = g.splitRequestModel(t, definitions, parameterFields)
+	}
+	if err != nil {
+		return routeDefinition{}, err
+	}
+	if !body {
+		ret.Schema = nil
+	}
+	if !params {
+		ret.Params = nil
+	}
+	return ret, nil
+}
+
+// requestFields reports whether the struct type t has fields documented as properties of the body,
+// a field tagged `in:body` and fields tagged with another `in`.
+func requestFields(t reflect.Type) (properties bool, bodyField bool, parameters bool, err error) {
+	fields, err := structFields(t)
+	if err != nil {
+		return false, false, false, err
+	}
+	for _, field := range fields {
+		var o *options
+		if !field.allOf {
+			o, err = parseTagOptions(field.declaring, field.StructField)
+			if err != nil {
+				return false, false, false, err
+			}
+		}
+		switch {
+		case o == nil || o.In == "":
+			properties = true
+		case o.In == "body":
+			bodyField = true
+		default:
+			parameters = true
+		}
+	}
+	return properties, bodyField, parameters, nil
+}
+
+// splitRequestModel reflects the fields of the request model t selected by selection, which is bodyFields,
+// taggedFields or parameterFields. The body definition is named after t, unless t is documented with all of
+// its fields elsewhere, in which case the body schema is inlined.
+func (g *Generator) splitRequestModel(t reflect.Type, definitions *definitionSet, selection fieldSelection) (routeDefinition, error) {
+	var name string
+	if selection == bodyFields {
+		name = definitions.name(t)
+	} else {
+		// no definition is added, the name only prefixes the names of anonymous structs
+		name = definitions.candidateName(t)
+	}
+	definition, params, err := g.buildStructDefinition(t, definitions, name, selection)
+	if err != nil {
+		return routeDefinition{}, err
+	}
+
+	ret := routeDefinition{}
+	bodyField := ""
+	for _, param := range params {
+		if param.In != "body" {
+			ret.Params = append(ret.Params, param)
+			continue
+		}
+		if bodyField != "" {
+			return routeDefinition{}, fmt.Errorf("%s: fields %s and %s are both tagged in:body", t, bodyField, param.Name)
+		}
+		bodyField = param.Name
+		ret.Schema = param.Schema
+	}
+	if selection != bodyFields {
+		return ret, nil
+	}
+	if bodyField != "" {
+		return routeDefinition{}, fmt.Errorf("%s: field %s is tagged in:body, the other fields must be parameters", t, bodyField)
+	}
+
+	if definitions.documented[t] {
+		schema := definitionSchema(*definition)
+		ret.Schema = &schema
+		return ret, nil
+	}
+	if !definitions.reflected[t] {
+		definitions.reflected[t] = true
+		definitions.definitions[name] = *definition
+	}
+	ret.Schema = &swagger.Schema{Ref: "#/definitions/" + name}
+	return ret, nil
+}
+
+// modelType resolves the struct or registered type of a model. A nil model resolves to a nil type.
+func (g *Generator) modelType(v interface{}) (reflect.Type, error) {
+	if v == nil {
+		return nil, nil
+	}
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	_, registered, _ := g.lookupType(t)
+	for !registered && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		_, registered, _ = g.lookupType(t)
+	}
+	if _, ok := g.interfaces[t]; ok {
+		return t, nil
+	}
+	if !registered && t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported model type %s: expected a struct, a pointer to a struct or a reflect.Type", t)
+	}
+	return t, nil
+}
+
+// parseStructType adds the definition of the struct type t to definitions, unless it has already been added,
+// and returns its name.
+func (g *Generator) parseStructType(t reflect.Type, definitions *definitionSet) (string, error) {
+	name := definitions.name(t)
+	if definitions.reflected[t] {
+		return name, nil
+	}
+	definitions.reflected[t] = true
+	definition, _, err := g.buildStructDefinition(t, definitions, name, allFields)
+	if err != nil {
+		return "", err
+	}
+	definitions.definitions[name] = *definition
+	return name, nil
 }
 
-func parseStructToDefinition(v interface{}) routeDefinition {
-	fields := reflect.TypeOf(v)
-	values := reflect.ValueOf(v)
-	structName := values.Type().Name()
+// buildStructDefinition reflects the fields of the struct type t selected by selection into a definition
+// and parameters. name is the definition name, used as context to name the anonymous structs of its fields.
+// Malformed swagger tags are returned as a *TagError.
+func (g *Generator) buildStructDefinition(fields reflect.Type, definitions *definitionSet, name string, selection fieldSelection) (*swagger.Definition, []swagger.PathItemParameter, error) {
+	context := definitions.context
+	defer func() { definitions.context = context }()
 	defType := "object"
 
 	routeParams := []swagger.PathItemParameter{}
 	definition := &swagger.Definition{Type: defType}
+	composed := []swagger.Schema{}
+	unknown := []string{}
 
-	for i := 0; i < values.NumField(); i++ {
-		field := fields.Field(i)
-		value := values.Field(i)
-
-		var reflectedType string
-		var reflectedFormat string
-		switch value.Kind() {
-		case reflect.Bool:
-			reflectedType = "boolean"
-		case reflect.String:
-			reflectedType = "string"
-		case reflect.Int:
-			reflectedType = "integer"
-			reflectedFormat = "int32"
-		case reflect.Int32:
-			reflectedType = "integer"
-			reflectedFormat = "int32"
-		case reflect.Int64:
-			reflectedType = "long"
-			reflectedFormat = "int64"
-		case reflect.Float32:
-		case reflect.Float64:
-			reflectedType = "float"
-			reflectedFormat = "float"
-		default:
-			reflectedType = "string"
+	serialized, err := structFields(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, field := range serialized {
+		definitions.context = []string{name, field.name}
+		var paramOptions *options
+		if !field.allOf {
+			paramOptions, err = parseTagOptions(field.declaring, field.StructField)
+			if err != nil {
+				return nil, nil, err
+			}
+			if paramOptions != nil {
+				for _, key := range paramOptions.Unknown {
+					unknown = append(unknown, field.Name+"."+key)
+				}
+			}
+		}
+		isParam := selection != allFields && paramOptions != nil && paramOptions.In != ""
+		if selection == taggedFields && !isParam || selection == parameterFields && (!isParam || paramOptions.In == "body") {
+			continue
 		}
 
-		if reflectedFormat == "" {
-			reflectedFormat = strings.ToLower(field.Type.String())
+		if isParam && paramOptions.In != "body" && !g.isParameterType(field.Type) {
+			// checked before reflecting the field, so that no definition is added for it
+			err := fmt.Errorf("%s parameters must be primitives or arrays of primitives, %s is not", paramOptions.In, field.Type)
+			return nil, nil, fieldTagError(field.declaring, field.StructField, &TagError{Key: "in", Err: err})
 		}
 
-		prop := swagger.DefinitionProperty{
-			Type:   reflectedType,
-			Format: reflectedFormat,
+		prop, err := g.parseFieldType(field.Type, definitions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if field.allOf {
+			composed = append(composed, prop)
+			continue
+		}
+		if field.asString && isStringEncodable(field.Type) {
+			// the value is encoded in a JSON string, the format and bounds of the number no longer apply
+			prop = swagger.DefinitionProperty{Type: "string", Description: prop.Description}
 		}
 
-		tag := field.Tag.Get("swagger")
-		paramName := field.Name
-		paramOptions := parseParamsOptions(tag)
+		paramName := field.name
+		required := !field.omitEmpty
+		if g.ValidationTags {
+			paramOptions = mergeOptions(parseValidationTags(field.StructField), paramOptions)
+		}
 		if paramOptions != nil {
+			if paramOptions.RequiredSet {
+				required = paramOptions.Required
+			}
 
 			if paramOptions.Name != "" {
 				paramName = paramOptions.Name
 			}
 
-			routeParams = append(routeParams, swagger.PathItemParameter{
-				Required:         paramOptions.Required,
-				In:               paramOptions.In,
-				CollectionFormat: paramOptions.CollectionFormat,
-				Enum:             paramOptions.Enum,
-				Name:             paramName,
-				Type:             reflectedType,
-				Format:           field.Type.String(),
-			})
-			prop.Enum = paramOptions.Enum
+			if paramOptions.Inline != "" {
+				if definitions.fieldInlining[name] == nil {
+					definitions.fieldInlining[name] = make(map[string]InlineMode)
+				}
+				definitions.fieldInlining[name][paramName] = paramOptions.Inline
+			}
+
+			applyConstraints(&prop, paramOptions)
+			if err := applyEnum(&prop, paramOptions); err != nil {
+				return nil, nil, fieldTagError(field.declaring, field.StructField, err)
+			}
+			if err := applyAnnotations(&prop, paramOptions); err != nil {
+				return nil, nil, fieldTagError(field.declaring, field.StructField, err)
+			}
+
+			if isParam && paramOptions.In == "body" {
+				// the field represents the whole body rather than one of its properties
+				body := prop
+				routeParams = append(routeParams, swagger.PathItemParameter{
+					In:          "body",
+					Name:        paramName,
+					Description: prop.Description,
+					Required:    required,
+					Schema:      &body,
+				})
+				continue
+			}
+			if isParam {
+				routeParams = append(routeParams, swagger.PathItemParameter{
+					Required:         paramOptions.Required,
+					In:               paramOptions.In,
+					CollectionFormat: paramOptions.CollectionFormat,
+					Enum:             prop.Enum,
+					Name:             paramName,
+					Description:      prop.Description,
+					Type:             prop.Type,
+					Format:           prop.Format,
+					Items:            prop.Items,
+					Maximum:          prop.Maximum,
+					ExclusiveMaximum: prop.ExclusiveMaximum,
+					Minimum:          prop.Minimum,
+					ExclusiveMinimum: prop.ExclusiveMinimum,
+					MaxLength:        prop.MaxLength,
+					MinLength:        prop.MinLength,
+					Pattern:          prop.Pattern,
+					MaxItems:         prop.MaxItems,
+					MinItems:         prop.MinItems,
+					UniqueItems:      prop.UniqueItems,
+					MultipleOf:       prop.MultipleOf,
+					Default:          prop.Default,
+					Example:          prop.Example,
+					Deprecated:       prop.Deprecated,
+				})
+				// parameters are sent in the path, query or headers instead of the body
+				continue
+			}
 		}
 
 		definition.AddProperty(paramName, prop)
+		if required {
+			definition.Required = append(definition.Required, paramName)
+		}
+	}
+
+	if len(unknown) > 0 {
+		g.warnTypef(definitions, fields, "%s: unknown swagger tag keys are ignored: %s", fields, strings.Join(unknown, ", "))
 	}
 
-	return routeDefinition{ModelName: &structName, Definition: definition, Params: routeParams}
+	if len(composed) > 0 {
+		// embedded structs tagged with allOf are referenced instead of having their fields promoted
+		own := swagger.Schema{Type: defType, Properties: definition.Properties, Required: definition.Required}
+		definition = &swagger.Definition{AllOf: append(composed, own)}
+	}
+
+	return definition, routeParams, nil
 }
 
-func parseParamsOptions(tag string) *options {
-	if tag == "" {
-		return nil
-	}
-	ret := &options{}
-	splitted := strings.Split(tag, ";")
-	for i := 0; i < len(splitted); i++ {
-		splitVar := strings.Split(splitted[i], ":")
-
-		switch splitVar[0] {
-		case "required":
-			{
-				p, err := strconv.ParseBool(splitVar[1])
-				if err == nil {
-					ret.Required = p
-				}
-				break
-			}
-		case "in":
-			{
-				p := splitVar[1]
-				ret.In = p
-				break
-			}
-		case "multiple":
-			{
-				p, err := strconv.ParseBool(splitVar[1])
-				if err == nil && p {
-					ret.CollectionFormat = "multi"
-				}
-				break
-			}
-		case "enum":
-			{
-				p := splitVar[1]
-				p = p[1 : len(p)-1]
-				val := []string{}
-				for _, v := range strings.Split(p, ",") {
-					val = append(val, v[1:len(v)-1])
-				}
-				ret.Enum = val //strings.Split(p, ",")
-				break
-			}
-		case "name":
-			{
-				p := splitVar[1]
-				ret.Name = p
-				break
-			}
-		default:
-			break
+// parseFieldType reflects a field's type into a definition property.
+// Definitions of nested structs are added to definitions.
+func (g *Generator) parseFieldType(t reflect.Type, definitions *definitionSet) (swagger.DefinitionProperty, error) {
+	if schema, ok := g.registeredType(t, definitions); ok {
+		return schema, nil
+	}
 
+	prop := swagger.DefinitionProperty{}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.parseFieldType(t.Elem(), definitions)
+	case reflect.Bool:
+		prop.Type = "boolean"
+	case reflect.String:
+		prop.Type = "string"
+	case reflect.Int8:
+		prop = integerProperty("int32", math.MinInt8, math.MaxInt8)
+	case reflect.Int16:
+		prop = integerProperty("int32", math.MinInt16, math.MaxInt16)
+	case reflect.Int32:
+		prop = integerProperty("int32", 0, 0)
+	case reflect.Int64:
+		prop = integerProperty("int64", 0, 0)
+	case reflect.Int:
+		// int is documented as int64 whatever the platform, so the spec does not depend on the build host
+		prop = integerProperty("int64", 0, 0)
+	case reflect.Uint8:
+		prop = integerProperty("int32", 0, math.MaxUint8)
+	case reflect.Uint16:
+		prop = integerProperty("int32", 0, math.MaxUint16)
+	case reflect.Uint32:
+		prop = integerProperty("int64", 0, math.MaxUint32)
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		// Swagger has no format for unsigned 64 bit integers
+		prop = integerProperty("", 0, 0)
+		prop.Minimum = float64Ptr(0)
+	case reflect.Float32:
+		prop.Type = "number"
+		prop.Format = "float"
+	case reflect.Float64:
+		prop.Type = "number"
+		prop.Format = "double"
+	case reflect.Slice, reflect.Array:
+		items, err := g.parseFieldType(t.Elem(), definitions)
+		if err != nil {
+			return prop, err
+		}
+		prop.Type = "array"
+		prop.Items = &items
+		if t.Kind() == reflect.Array {
+			prop.MinItems = t.Len()
+			prop.MaxItems = t.Len()
+		}
+		return prop, nil
+	case reflect.Map:
+		values, err := g.parseFieldType(t.Elem(), definitions)
+		if err != nil {
+			return prop, err
 		}
+		prop.Type = "object"
+		prop.AdditionalProperties = &values
+		return prop, nil
+	case reflect.Struct:
+		if definitions.reflected[t] {
+			// reference the definition already built, or under construction for recursive types
+			prop.Ref = "#/definitions/" + definitions.name(t)
+			return prop, nil
+		}
+		name, err := g.parseStructType(t, definitions)
+		if err != nil {
+			return prop, err
+		}
+		prop.Ref = "#/definitions/" + name
+		return prop, nil
+	case reflect.Interface:
+		return g.parseInterfaceType(t, definitions)
+	default:
+		prop.Type = "string"
 	}
-	return ret
+	return prop, nil
+}
+
+// isParameterType reports whether t can be documented as a path, query, header or formData parameter.
+// Swagger 2.0 only allows primitives and arrays of primitives outside of the body.
+func (g *Generator) isParameterType(t reflect.Type) bool {
+	if schema, ok, _ := g.lookupType(t); ok {
+		return isParameterSchema(schema)
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return g.isParameterType(t.Elem())
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// isParameterSchema reports whether schema is a primitive or an array of primitives.
+func isParameterSchema(schema swagger.Schema) bool {
+	switch schema.Type {
+	case "string", "number", "integer", "boolean":
+		return schema.Ref == ""
+	case "array":
+		return schema.Items != nil && isParameterSchema(*schema.Items)
+	}
+	return false
+}
+
+// applyConstraints sets the validation constraints of o on prop. Constraints on the values of an array,
+// such as pattern or maximum, are set on its items.
+func applyConstraints(prop *swagger.DefinitionProperty, o *options) {
+	if o.MaxItems != 0 {
+		prop.MaxItems = o.MaxItems
+	}
+	if o.MinItems != 0 {
+		prop.MinItems = o.MinItems
+	}
+	if o.UniqueItems {
+		prop.UniqueItems = true
+	}
+
+	values := valuesSchema(prop)
+	if o.Maximum != nil {
+		values.Maximum = o.Maximum
+		values.ExclusiveMaximum = o.ExclusiveMaximum
+	}
+	if o.Minimum != nil {
+		values.Minimum = o.Minimum
+		values.ExclusiveMinimum = o.ExclusiveMinimum
+	}
+	if o.MaxLength != 0 {
+		values.MaxLength = o.MaxLength
+	}
+	if o.MinLength != 0 {
+		values.MinLength = o.MinLength
+	}
+	if o.Pattern != "" {
+		values.Pattern = o.Pattern
+	}
+	if o.MultipleOf != 0 {
+		values.MultipleOf = o.MultipleOf
+	}
+	if o.Format != "" {
+		values.Format = o.Format
+	}
+}
+
+// valuesSchema returns the schema of the values of prop, which are its innermost items for arrays.
+// Items are copied so they can be modified without altering schemas shared with other properties.
+func valuesSchema(prop *swagger.DefinitionProperty) *swagger.DefinitionProperty {
+	values := prop
+	for values.Type == "array" && values.Items != nil {
+		items := *values.Items
+		values.Items = &items
+		values = &items
+	}
+	return values
+}
+
+// parseInterfaceType references the definition of a registered interface, adding the definitions of its
+// implementations. Interfaces that are not registered are documented as free-form.
+func (g *Generator) parseInterfaceType(t reflect.Type, definitions *definitionSet) (swagger.DefinitionProperty, error) {
+	polymorphic, ok := g.interfaces[t]
+	if !ok {
+		return swagger.DefinitionProperty{}, nil
+	}
+
+	baseRef := "#/definitions/" + definitions.name(t)
+	if definitions.reflected[t] {
+		return swagger.DefinitionProperty{Ref: baseRef}, nil
+	}
+	definitions.reflected[t] = true
+
+	values := []string{}
+	for value := range polymorphic.implementations {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	enum := []interface{}{}
+	for _, value := range values {
+		enum = append(enum, value)
+	}
+
+	base := swagger.Definition{Type: "object", Discriminator: polymorphic.discriminator}
+	base.AddProperty(polymorphic.discriminator, swagger.DefinitionProperty{Type: "string", Enum: enum})
+	base.Required = []string{polymorphic.discriminator}
+	definitions.definitions[definitions.name(t)] = base
+	definitions.polymorphic[definitions.name(t)] = true
+
+	for _, value := range values {
+		implementation := polymorphic.implementations[value]
+		name := definitions.name(implementation)
+		subtype := &swagger.Definition{Type: "object"}
+		if implementation.Kind() == reflect.Struct {
+			definitions.reflected[implementation] = true
+			var err error
+			subtype, _, err = g.buildStructDefinition(implementation, definitions, name, allFields)
+			if err != nil {
+				return swagger.DefinitionProperty{}, err
+			}
+		}
+		definitions.definitions[name] = composeDefinition(baseRef, *subtype)
+		definitions.polymorphic[name] = true
+	}
+	return swagger.DefinitionProperty{Ref: baseRef}, nil
+}
+
+// definitionSchema returns the schema of definition, to be used in place of a reference to it.
+func definitionSchema(definition swagger.Definition) swagger.Schema {
+	return swagger.Schema{
+		Type:                 definition.Type,
+		Properties:           definition.Properties,
+		Required:             definition.Required,
+		AdditionalProperties: definition.AdditionalProperties,
+		AllOf:                definition.AllOf,
+	}
+}
+
+// composeDefinition composes definition with the definition referenced by ref using allOf.
+func composeDefinition(ref string, definition swagger.Definition) swagger.Definition {
+	if len(definition.AllOf) > 0 {
+		definition.AllOf = append([]swagger.Schema{{Ref: ref}}, definition.AllOf...)
+		return definition
+	}
+	own := swagger.Schema{
+		Type:                 definition.Type,
+		Properties:           definition.Properties,
+		Required:             definition.Required,
+		AdditionalProperties: definition.AdditionalProperties,
+	}
+	return swagger.Definition{AllOf: []swagger.Schema{{Ref: ref}, own}}
+}
+
+// integerProperty creates an integer property. Bounds are only set if min and max differ.
+func integerProperty(format string, min float64, max float64) swagger.DefinitionProperty {
+	prop := swagger.DefinitionProperty{Type: "integer", Format: format}
+	if min != max {
+		prop.Minimum = float64Ptr(min)
+		prop.Maximum = float64Ptr(max)
+	}
+	return prop
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
 }
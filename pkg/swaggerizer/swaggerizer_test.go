@@ -1,7 +1,15 @@
 package swaggerizer
 
 import (
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/erikperez/go-swaggerize/pkg/swagger"
 )
@@ -65,11 +73,11 @@ func TestSwaggerize(t *testing.T) {
 	})
 	o, err := Swaggerize(swag, routes)
 	if err != nil {
-		t.Logf(err.Error())
+		t.Log(err.Error())
 		t.Fail()
 		return
 	}
-	t.Logf(o)
+	t.Log(o)
 }
 
 type message struct {
@@ -96,3 +104,1639 @@ type putUserResponse struct {
 	Success bool
 	Error   string
 }
+
+type address struct {
+	Street string
+	City   string
+}
+
+type customer struct {
+	Name    string
+	Address address
+}
+
+func TestSwaggerizeNestedStruct(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/customer", Verb: "post", Model: customer{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prop := swag.Definitions["customer"].Properties["Address"]
+	if prop.Ref != "#/definitions/address" || prop.Type != "" {
+		t.Errorf("expected Address to reference #/definitions/address, got %+v", prop)
+	}
+	if _, ok := swag.Definitions["address"].Properties["City"]; !ok {
+		t.Errorf("expected address definition to be registered, got %+v", swag.Definitions)
+	}
+}
+
+func TestSwaggerizeStructParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+	}{
+		{"struct", struct {
+			Address address `swagger:"in:query"`
+		}{}},
+		{"map", struct {
+			Labels map[string]string `swagger:"in:header"`
+		}{}},
+		{"array of structs", struct {
+			Addresses []*address `swagger:"in:formData"`
+		}{}},
+	}
+	for _, test := range tests {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		_, err := Swaggerize(swag, []Route{{Route: "/customer", Verb: "get", Model: test.model}})
+		routeErr, ok := err.(*RouteError)
+		if !ok || !strings.Contains(err.Error(), "must be primitives or arrays of primitives") {
+			t.Errorf("%s: expected a parameter to be rejected, got %v", test.name, err)
+			continue
+		}
+		if tagErr, ok := routeErr.Err.(*TagError); !ok || tagErr.Key != "in" {
+			t.Errorf("%s: expected a *TagError on the in key, got %#v", test.name, routeErr.Err)
+		}
+		if _, ok := swag.Definitions["address"]; ok {
+			t.Errorf("%s: expected no definition for the parameter", test.name)
+		}
+	}
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/invoice", Verb: "get", Model: struct {
+		Since time.Time `swagger:"in:query"`
+		IDs   [][]int   `swagger:"in:query"`
+		Body  address   `swagger:"in:body"`
+	}{}}})
+	if err != nil {
+		t.Errorf("expected primitives, arrays of primitives and body fields to be accepted, got %v", err)
+	}
+}
+
+type order struct {
+	Lines     []address
+	Tags      []string
+	Matrix    [][]int
+	Checksums [4]string
+}
+
+func TestSwaggerizeArrays(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/order", Verb: "post", Model: order{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["order"].Properties
+	if p := props["Lines"]; p.Type != "array" || p.Items == nil || p.Items.Ref != "#/definitions/address" {
+		t.Errorf("expected Lines to be an array of address, got %+v", p)
+	}
+	if p := props["Tags"]; p.Type != "array" || p.Format != "" || p.Items == nil || p.Items.Type != "string" {
+		t.Errorf("expected Tags to be an array of string, got %+v", p)
+	}
+	if p := props["Matrix"]; p.Items == nil || p.Items.Type != "array" || p.Items.Items == nil || p.Items.Items.Type != "integer" {
+		t.Errorf("expected Matrix to be an array of array of integer, got %+v", p)
+	}
+	if p := props["Checksums"]; p.Type != "array" || p.MinItems != 4 || p.MaxItems != 4 {
+		t.Errorf("expected Checksums to have 4 items, got %+v", p)
+	}
+}
+
+type labelled struct {
+	Labels   map[string]string
+	Owners   map[string]customer
+	Versions map[string][]int
+}
+
+func TestSwaggerizeMaps(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/labelled", Verb: "post", Model: labelled{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["labelled"].Properties
+	if p := props["Labels"]; p.Type != "object" || p.AdditionalProperties == nil || p.AdditionalProperties.Type != "string" {
+		t.Errorf("expected Labels to be an object of string, got %+v", p)
+	}
+	if p := props["Owners"]; p.AdditionalProperties == nil || p.AdditionalProperties.Ref != "#/definitions/customer" {
+		t.Errorf("expected Owners to be an object of customer, got %+v", p)
+	}
+	if p := props["Versions"]; p.AdditionalProperties == nil || p.AdditionalProperties.Type != "array" {
+		t.Errorf("expected Versions to be an object of array, got %+v", p)
+	}
+}
+
+type pointered struct {
+	Customer *customer
+	Note     *string
+}
+
+func TestSwaggerizePointerModels(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Route: "/pointer", Verb: "post", Model: &pointered{}},
+		// putUser has a username path parameter, which must match a variable of the path template
+		{Route: "/nil/{username}", Verb: "post", Model: (*putUser)(nil)},
+		{Route: "/type", Verb: "post", Model: reflect.TypeOf(message{}), Responses: []Response{
+			{Name: "200", Model: &putUserResponse{}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"pointered", "putUser", "message", "putUserResponse", "customer"} {
+		if _, ok := swag.Definitions[name]; !ok {
+			t.Errorf("expected definition %s, got %+v", name, swag.Definitions)
+		}
+	}
+	if p := swag.Definitions["pointered"].Properties["Customer"]; p.Ref != "#/definitions/customer" {
+		t.Errorf("expected Customer to reference #/definitions/customer, got %+v", p)
+	}
+	if p := swag.Definitions["pointered"].Properties["Note"]; p.Type != "string" {
+		t.Errorf("expected Note to be a string, got %+v", p)
+	}
+}
+
+func TestSwaggerizeUnsupportedModel(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/invalid", Verb: "post", Model: 42}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported model type int") {
+		t.Errorf("expected an unsupported model type error, got %v", err)
+	}
+}
+
+type jsonTagged struct {
+	MessageID string  `json:"messageid"`
+	Note      string  `json:"note,omitempty"`
+	Amount    int64   `json:"amount,string"`
+	Level     int8    `json:"level,string,omitempty"`
+	Secret    string  `json:"-"`
+	Dash      string  `json:"-,"`
+	Renamed   string  `json:"renamed" swagger:"name:alias"`
+	Optional  *string `json:"optional" swagger:"required:false"`
+	internal  string
+}
+
+func TestSwaggerizeJSONTags(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/tagged", Verb: "post", Model: jsonTagged{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := swag.Definitions["jsonTagged"]
+	for _, name := range []string{"messageid", "note", "amount", "-", "alias", "optional"} {
+		if _, ok := definition.Properties[name]; !ok {
+			t.Errorf("expected property %s, got %+v", name, definition.Properties)
+		}
+	}
+	for _, name := range []string{"MessageID", "Secret", "renamed", "internal"} {
+		if _, ok := definition.Properties[name]; ok {
+			t.Errorf("expected no property %s, got %+v", name, definition.Properties)
+		}
+	}
+	if p := definition.Properties["amount"]; p.Type != "string" || p.Format != "" {
+		t.Errorf("expected amount to be string encoded, got %+v", p)
+	}
+	if p := definition.Properties["level"]; p.Type != "string" || p.Format != "" || p.Minimum != nil || p.Maximum != nil {
+		t.Errorf("expected level to be string encoded without bounds, got %+v", p)
+	}
+	if !reflect.DeepEqual(definition.Required, []string{"messageid", "amount", "-", "alias"}) {
+		t.Errorf("unexpected required properties %v", definition.Required)
+	}
+}
+
+type numbers struct {
+	Int     int
+	Int8    int8
+	Int32   int32
+	Int64   int64
+	Uint8   uint8
+	Uint32  uint32
+	Uint64  uint64
+	Float32 float32
+	Float64 float64
+}
+
+func TestSwaggerizeNumbers(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/numbers", Verb: "post", Model: numbers{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["numbers"].Properties
+	expected := map[string][2]string{
+		"Int":     {"integer", "int64"},
+		"Int8":    {"integer", "int32"},
+		"Int32":   {"integer", "int32"},
+		"Int64":   {"integer", "int64"},
+		"Uint8":   {"integer", "int32"},
+		"Uint32":  {"integer", "int64"},
+		"Uint64":  {"integer", ""},
+		"Float32": {"number", "float"},
+		"Float64": {"number", "double"},
+	}
+	for name, typeAndFormat := range expected {
+		if p := props[name]; p.Type != typeAndFormat[0] || p.Format != typeAndFormat[1] {
+			t.Errorf("expected %s to be %v, got %+v", name, typeAndFormat, p)
+		}
+	}
+	if p := props["Int8"]; p.Minimum == nil || *p.Minimum != -128 || p.Maximum == nil || *p.Maximum != 127 {
+		t.Errorf("expected Int8 to be bounded, got %+v", p)
+	}
+	if p := props["Uint64"]; p.Minimum == nil || *p.Minimum != 0 || p.Maximum != nil {
+		t.Errorf("expected Uint64 to have minimum 0, got %+v", p)
+	}
+	if p := props["Int64"]; p.Minimum != nil || p.Maximum != nil {
+		t.Errorf("expected Int64 to be unbounded, got %+v", p)
+	}
+}
+
+type wellKnown struct {
+	CreatedAt  time.Time
+	DeletedAt  *time.Time
+	Timeout    time.Duration
+	Address    net.IP
+	Homepage   url.URL
+	Payload    json.RawMessage
+	Avatar     []byte
+	Balance    big.Int
+	Updated    []time.Time
+	Attachment string    `swagger:"in:query"`
+	Since      time.Time `swagger:"in:query"`
+}
+
+func TestSwaggerizeWellKnownTypes(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/wellknown", Verb: "put", Model: wellKnown{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["wellKnown"].Properties
+	expected := map[string][2]string{
+		"CreatedAt": {"string", "date-time"},
+		"DeletedAt": {"string", "date-time"},
+		"Timeout":   {"integer", "int64"},
+		"Address":   {"string", "ipv4"},
+		"Homepage":  {"string", "uri"},
+		"Payload":   {"object", ""},
+		"Avatar":    {"string", "byte"},
+		"Balance":   {"integer", ""},
+	}
+	for name, typeAndFormat := range expected {
+		if p := props[name]; p.Type != typeAndFormat[0] || p.Format != typeAndFormat[1] {
+			t.Errorf("expected %s to be %v, got %+v", name, typeAndFormat, p)
+		}
+	}
+	if p := props["Updated"]; p.Items == nil || p.Items.Format != "date-time" {
+		t.Errorf("expected Updated to be an array of date-time, got %+v", p)
+	}
+
+	param, ok := findParameter(swag.Paths["/wellknown"].Put.Parameters, "Since")
+	if !ok || param.Type != "string" || param.Format != "date-time" {
+		t.Errorf("expected Since parameter to be a date-time, got %+v", param)
+	}
+	if _, ok := swag.Definitions["Time"]; ok {
+		t.Errorf("expected no definition for time.Time")
+	}
+}
+
+// findParameter returns the parameter named name.
+func findParameter(params []swagger.PathItemParameter, name string) (swagger.PathItemParameter, bool) {
+	for _, param := range params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return swagger.PathItemParameter{}, false
+}
+
+type money struct {
+	Amount   int64
+	Currency string
+}
+
+type uuid [16]byte
+
+type invoice struct {
+	ID     uuid `swagger:"in:query"`
+	Total  money
+	Refund *money
+}
+
+func TestGeneratorRegisterType(t *testing.T) {
+	generator := NewGenerator().
+		RegisterType(reflect.TypeOf(money{}), swagger.Schema{Type: "string", Pattern: "^[0-9]+ [A-Z]{3}$"}).
+		RegisterType(reflect.TypeOf(uuid{}), swagger.Schema{Type: "string", Format: "uuid"})
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{{
+		Route: "/invoice",
+		Verb:  "post",
+		Model: invoice{},
+		Responses: []Response{
+			{Name: "200", Model: uuid{}},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["invoice"].Properties
+	if p := props["Total"]; p.Type != "string" || p.Pattern == "" {
+		t.Errorf("expected Total to use the registered schema, got %+v", p)
+	}
+	if p := props["Refund"]; p.Type != "string" || p.Pattern == "" {
+		t.Errorf("expected Refund to use the registered schema, got %+v", p)
+	}
+	if _, ok := swag.Definitions["money"]; ok {
+		t.Errorf("expected no definition for a registered type")
+	}
+	operation := swag.Paths["/invoice"].Post
+	if param, ok := findParameter(operation.Parameters, "ID"); !ok || param.Format != "uuid" {
+		t.Errorf("expected ID parameter to use the registered schema, got %+v", param)
+	}
+	if schema := operation.Responses["200"].Schema; schema.Format != "uuid" {
+		t.Errorf("expected the response to use the registered schema, got %+v", schema)
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	if _, err := Swaggerize(swag, []Route{{Route: "/invoice", Verb: "post", Model: invoice{}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := swag.Definitions["money"]; !ok {
+		t.Errorf("expected the default generator not to use the registered types")
+	}
+}
+
+func TestGeneratorZeroValue(t *testing.T) {
+	warnings := []string{}
+	generator := &Generator{Warn: func(warning string) {
+		warnings = append(warnings, warning)
+	}}
+	generator.
+		RegisterType(reflect.TypeOf(money{}), swagger.Schema{Type: "string", Pattern: "^[0-9]+ [A-Z]{3}$"}).
+		RegisterInterface(reflect.TypeOf((*event)(nil)).Elem(), "type", map[string]interface{}{"orderCreated": orderCreated{}}).
+		SetInlineMode(reflect.TypeOf(address{}), InlineAlways)
+
+	type zeroValue struct {
+		Total   money
+		Extra   opaque
+		Payload event
+		Address address
+	}
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	if _, err := generator.Swaggerize(swag, []Route{{Route: "/zero", Verb: "post", Model: zeroValue{}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["zeroValue"].Properties
+	if p := props["Total"]; p.Type != "string" || p.Pattern == "" {
+		t.Errorf("expected Total to use the registered schema, got %+v", p)
+	}
+	if p := props["Payload"]; p.Ref != "#/definitions/event" {
+		t.Errorf("expected Payload to reference the registered interface, got %+v", p)
+	}
+	if p := props["Address"]; p.Ref != "" || p.Type != "object" {
+		t.Errorf("expected Address to be inlined, got %+v", p)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a single warning for opaque, got %v", warnings)
+	}
+}
+
+type phoneNumber struct {
+	CountryCode int
+	Number      string
+}
+
+func (phoneNumber) SwaggerSchema() swagger.Schema {
+	return swagger.Schema{Type: "string", Format: "phone"}
+}
+
+type decimal struct {
+	Unscaled int64
+	Scale    int
+}
+
+func (*decimal) SwaggerSchema() swagger.Schema {
+	return swagger.Schema{Type: "string", Pattern: "^-?[0-9]+(\\.[0-9]+)?$"}
+}
+
+type contact struct {
+	Phone    phoneNumber
+	Mobile   *phoneNumber
+	Discount decimal
+	Rate     *decimal
+}
+
+func TestSwaggerizeSchemaProvider(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/contact", Verb: "post", Model: contact{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["contact"].Properties
+	for _, name := range []string{"Phone", "Mobile"} {
+		if p := props[name]; p.Type != "string" || p.Format != "phone" {
+			t.Errorf("expected %s to use the provided schema, got %+v", name, p)
+		}
+	}
+	for _, name := range []string{"Discount", "Rate"} {
+		if p := props[name]; p.Type != "string" || p.Pattern == "" {
+			t.Errorf("expected %s to use the provided schema, got %+v", name, p)
+		}
+	}
+	if len(swag.Definitions) != 1 {
+		t.Errorf("expected provided schemas not to be reflected, got %+v", swag.Definitions)
+	}
+}
+
+type orderID struct {
+	value int64
+}
+
+func (id orderID) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(id.value, 36)), nil
+}
+
+type status int
+
+func (s *status) MarshalText() ([]byte, error) {
+	return []byte("active"), nil
+}
+
+type opaque struct {
+	Fields map[string]interface{}
+}
+
+func (o opaque) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Fields)
+}
+
+type marshaling struct {
+	ID      orderID
+	Status  *status
+	Code    status
+	Extra   opaque
+	Extras  []opaque
+	Created time.Time
+}
+
+func TestSwaggerizeMarshalers(t *testing.T) {
+	warnings := []string{}
+	generator := NewGenerator()
+	generator.Warn = func(warning string) {
+		warnings = append(warnings, warning)
+	}
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{
+		{Route: "/marshaling", Verb: "post", Model: marshaling{}},
+		{Route: "/marshaling", Verb: "put", Model: marshaling{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["marshaling"].Properties
+	for _, name := range []string{"ID", "Status"} {
+		if p := props[name]; p.Type != "string" {
+			t.Errorf("expected %s to be a string, got %+v", name, p)
+		}
+	}
+	// encoding/json only calls the pointer receiver MarshalText of status on pointers.
+	if p := props["Code"]; p.Type != "integer" {
+		t.Errorf("expected Code to be an integer, got %+v", p)
+	}
+	if p := props["Extra"]; !reflect.DeepEqual(p, swagger.Schema{}) {
+		t.Errorf("expected Extra to be free-form, got %+v", p)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "swaggerizer.opaque") {
+		t.Errorf("expected a single warning for opaque, got %v", warnings)
+	}
+}
+
+func TestSwaggerizeSetWarn(t *testing.T) {
+	warnings := []string{}
+	SetWarn(func(warning string) {
+		warnings = append(warnings, warning)
+	})
+	defer SetWarn(nil)
+
+	type freeForm struct {
+		Extra opaque
+	}
+	for i := 0; i < 2; i++ {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		if _, err := Swaggerize(swag, []Route{{Route: "/free", Verb: "post", Model: freeForm{}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// warnings are raised once per call, so regenerating the model raises them again
+	if len(warnings) != 2 {
+		t.Errorf("expected the warning to be passed to the function set on every call, got %v", warnings)
+	}
+}
+
+type baseEntity struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Audit struct {
+	UpdatedBy string `json:"updatedBy,omitempty"`
+}
+
+type article struct {
+	baseEntity
+	*Audit
+	ID    int    `json:"articleId"`
+	Title string `json:"title"`
+}
+
+type composedArticle struct {
+	baseEntity `swagger:"allOf:true"`
+	Title      string `json:"title"`
+}
+
+func TestSwaggerizeEmbeddedStructs(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/article", Verb: "post", Model: article{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := swag.Definitions["article"]
+	for _, name := range []string{"id", "createdAt", "updatedBy", "articleId", "title"} {
+		if _, ok := definition.Properties[name]; !ok {
+			t.Errorf("expected promoted property %s, got %+v", name, definition.Properties)
+		}
+	}
+	if _, ok := definition.Properties["baseEntity"]; ok {
+		t.Errorf("expected baseEntity to be promoted, got %+v", definition.Properties)
+	}
+	if _, ok := swag.Definitions["baseEntity"]; ok {
+		t.Errorf("expected no baseEntity definition for promoted fields")
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	_, err = Swaggerize(swag, []Route{{Route: "/composed", Verb: "post", Model: composedArticle{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	composed := swag.Definitions["composedArticle"]
+	if len(composed.AllOf) != 2 || composed.AllOf[0].Ref != "#/definitions/baseEntity" {
+		t.Fatalf("expected composedArticle to be composed of baseEntity, got %+v", composed)
+	}
+	if _, ok := composed.AllOf[1].Properties["title"]; !ok || len(composed.AllOf[1].Properties) != 1 {
+		t.Errorf("expected own properties in allOf, got %+v", composed.AllOf[1])
+	}
+	if _, ok := swag.Definitions["baseEntity"].Properties["createdAt"]; !ok {
+		t.Errorf("expected baseEntity definition, got %+v", swag.Definitions)
+	}
+}
+
+type timestamps struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type created struct {
+	timestamps
+	By string `json:"createdBy"`
+}
+
+type published struct {
+	timestamps
+	On string `json:"publishedOn"`
+}
+
+type draft struct {
+	Secret string `json:"secret"`
+}
+
+type post struct {
+	created
+	published
+	*draft
+	Title string `json:"title"`
+}
+
+func TestSwaggerizeEmbeddingConflicts(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/post", Verb: "post", Model: post{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	properties := swag.Definitions["post"].Properties
+	for _, name := range []string{"createdBy", "publishedOn", "title"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("expected promoted property %s, got %+v", name, properties)
+		}
+	}
+	// timestamps is embedded twice at the same depth, so encoding/json drops createdAt as ambiguous.
+	if _, ok := properties["createdAt"]; ok {
+		t.Errorf("expected the conflicting createdAt to be dropped, got %+v", properties)
+	}
+	if _, ok := properties["secret"]; ok {
+		t.Errorf("expected the fields of an embedded pointer to an unexported struct to be ignored, got %+v", properties)
+	}
+}
+
+type category struct {
+	Name     string
+	Children []category
+	Parent   *category
+}
+
+type employee struct {
+	Name       string
+	Department *department
+}
+
+type department struct {
+	Manager   employee
+	Employees []employee
+}
+
+func TestSwaggerizeRecursiveTypes(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Route: "/category", Verb: "post", Model: category{}},
+		{Route: "/employee", Verb: "post", Model: employee{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["category"].Properties
+	if p := props["Children"]; p.Items == nil || p.Items.Ref != "#/definitions/category" {
+		t.Errorf("expected Children to reference category, got %+v", p)
+	}
+	if p := props["Parent"]; p.Ref != "#/definitions/category" {
+		t.Errorf("expected Parent to reference category, got %+v", p)
+	}
+	if p := swag.Definitions["employee"].Properties["Department"]; p.Ref != "#/definitions/department" {
+		t.Errorf("expected Department to reference department, got %+v", p)
+	}
+	if p := swag.Definitions["department"].Properties["Manager"]; p.Ref != "#/definitions/employee" {
+		t.Errorf("expected Manager to reference employee, got %+v", p)
+	}
+}
+
+type event interface {
+	EventType() string
+}
+
+type orderCreated struct {
+	Type    string `json:"type"`
+	OrderID string `json:"orderId"`
+}
+
+func (orderCreated) EventType() string { return "orderCreated" }
+
+type orderCancelled struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (*orderCancelled) EventType() string { return "orderCancelled" }
+
+func TestSwaggerizeInvalidInterfaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator *Generator
+		expected  string
+	}{
+		{
+			name:      "not an interface",
+			generator: NewGenerator().RegisterInterface(reflect.TypeOf(orderCreated{}), "type", nil),
+			expected:  "swaggerizer.orderCreated is not an interface",
+		},
+		{
+			name: "not implemented",
+			generator: NewGenerator().RegisterInterface(reflect.TypeOf((*event)(nil)).Elem(), "type", map[string]interface{}{
+				"orderCancelled": orderCancelled{},
+			}),
+			expected: "swaggerizer.orderCancelled does not implement swaggerizer.event",
+		},
+	}
+	for _, test := range tests {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		_, err := test.generator.Swaggerize(swag, []Route{{Route: "/webhook", Verb: "post", Model: webhook{}}})
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+type webhook struct {
+	ID       string      `json:"id"`
+	Payload  event       `json:"payload"`
+	Metadata interface{} `json:"metadata"`
+}
+
+func TestSwaggerizePolymorphicInterfaces(t *testing.T) {
+	generator := NewGenerator().RegisterInterface(reflect.TypeOf((*event)(nil)).Elem(), "type", map[string]interface{}{
+		"orderCreated":   orderCreated{},
+		"orderCancelled": &orderCancelled{},
+	})
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{{Route: "/webhook", Verb: "post", Model: webhook{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["webhook"].Properties
+	if p := props["payload"]; p.Ref != "#/definitions/event" {
+		t.Errorf("expected payload to reference event, got %+v", p)
+	}
+	if p := props["metadata"]; !reflect.DeepEqual(p, swagger.Schema{}) {
+		t.Errorf("expected metadata to be free-form, got %+v", p)
+	}
+
+	base := swag.Definitions["event"]
+	if base.Discriminator != "type" || !reflect.DeepEqual(base.Required, []string{"type"}) {
+		t.Errorf("expected event to be discriminated by type, got %+v", base)
+	}
+	for _, name := range []string{"orderCreated", "orderCancelled"} {
+		subtype := swag.Definitions[name]
+		if len(subtype.AllOf) != 2 || subtype.AllOf[0].Ref != "#/definitions/event" || len(subtype.AllOf[1].Properties) != 2 {
+			t.Errorf("expected %s to be composed of event, got %+v", name, subtype)
+		}
+	}
+}
+
+type shipment struct {
+	Destination struct {
+		City string
+	}
+}
+
+func TestSwaggerizeDefinitionNames(t *testing.T) {
+	routes := []Route{{Route: "/customer", Verb: "post", Model: customer{}}}
+	type customer struct {
+		Nickname string
+	}
+	routes = append(routes,
+		Route{Route: "/other", Verb: "post", Model: customer{}},
+		Route{Route: "/shipment", Verb: "post", Model: shipment{}, Responses: []Response{
+			{Name: "200", Model: struct{ Accepted bool }{}},
+		}},
+	)
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	if _, err := Swaggerize(swag, routes); err != nil {
+		t.Fatal(err)
+	}
+
+	// both customers share their package path, so they are numbered in the order they are reflected
+	qualified := "github.com.erikperez.go-swaggerize.pkg.swaggerizer.customer"
+	if _, ok := swag.Definitions[qualified].Properties["Address"]; !ok {
+		t.Errorf("expected the first customer to be qualified, got %+v", swag.Definitions)
+	}
+	if _, ok := swag.Definitions[qualified+"_2"].Properties["Nickname"]; !ok {
+		t.Errorf("expected the colliding customer to be numbered, got %+v", swag.Definitions)
+	}
+	if _, ok := swag.Definitions["customer"]; ok {
+		t.Errorf("expected no customer to keep the short name, got %+v", swag.Definitions)
+	}
+	if p := swag.Definitions["shipment"].Properties["Destination"]; p.Ref != "#/definitions/shipment_Destination" {
+		t.Errorf("expected the anonymous struct to be named after its property, got %+v", p)
+	}
+	if _, ok := swag.Definitions["post_shipment_response_200"]; !ok {
+		t.Errorf("expected the anonymous response to be named after its route, got %+v", swag.Definitions)
+	}
+}
+
+// License shares its name with swagger.License.
+type License struct {
+	Key string
+}
+
+func TestSwaggerizeCollidingNames(t *testing.T) {
+	ours := Route{Route: "/ours", Verb: "post", Model: License{}}
+	theirs := Route{Route: "/theirs", Verb: "post", Model: swagger.License{}}
+	for _, routes := range [][]Route{{ours, theirs}, {theirs, ours}} {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		if _, err := Swaggerize(swag, routes); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := swag.Definitions["swaggerizer.License"].Properties["Key"]; !ok {
+			t.Errorf("expected License to be qualified by its package, got %+v", swag.Definitions)
+		}
+		if _, ok := swag.Definitions["swagger.License"].Properties["name"]; !ok {
+			t.Errorf("expected swagger.License to be qualified by its package, got %+v", swag.Definitions)
+		}
+		if _, ok := swag.Definitions["License"]; ok {
+			t.Errorf("expected no type to keep the short name, got %+v", swag.Definitions)
+		}
+	}
+}
+
+func TestSwaggerizeExistingDefinitions(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	swag.AddDefinition("address", swagger.Definition{Type: "string"})
+	_, err := Swaggerize(swag, []Route{{Route: "/customer", Verb: "post", Model: customer{}}})
+	if err == nil || !strings.Contains(err.Error(), "definition address is already defined") {
+		t.Fatalf("expected a definition collision error, got %v", err)
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	routes := []Route{{Route: "/customer", Verb: "post", Model: customer{}}}
+	if _, err := Swaggerize(swag, routes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGenerator().Swaggerize(swag, []Route{{Route: "/other", Verb: "put", Model: customer{}}}); err != nil {
+		t.Errorf("expected identical definitions to be accepted, got %v", err)
+	}
+}
+
+func TestQualifiedNames(t *testing.T) {
+	names := qualifiedNames("Page[github.com/acme/models.Order]", "github.com/acme/pagination")
+	expected := []string{"Page_Order", "pagination.Page_models.Order", "github.com.acme.pagination.Page_models.Order"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+type coordinates struct {
+	Lat float64
+	Lng float64
+}
+
+type dimensions struct {
+	Width  int
+	Height int
+}
+
+type color struct {
+	Name string
+}
+
+type store struct {
+	Location  coordinates
+	Entrances []coordinates
+	Size      dimensions `swagger:"inline:always"`
+	Primary   color
+	Secondary color
+	Owner     customer `swagger:"inline:never"`
+}
+
+func TestSwaggerizeInlining(t *testing.T) {
+	generator := NewGenerator().
+		SetInlineMode(reflect.TypeOf(coordinates{}), InlineAlways).
+		SetInlineMode(reflect.TypeOf(color{}), InlineOnce).
+		SetInlineMode(reflect.TypeOf(customer{}), InlineAlways).
+		SetInlineMode(reflect.TypeOf(address{}), InlineOnce)
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{{Route: "/store", Verb: "post", Model: store{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["store"].Properties
+	if p := props["Location"]; p.Ref != "" || p.Type != "object" || len(p.Properties) != 2 {
+		t.Errorf("expected Location to be inlined, got %+v", p)
+	}
+	if p := props["Entrances"]; p.Items == nil || p.Items.Ref != "" || len(p.Items.Properties) != 2 {
+		t.Errorf("expected Entrances items to be inlined, got %+v", p)
+	}
+	if p := props["Size"]; p.Ref != "" || len(p.Properties) != 2 {
+		t.Errorf("expected Size to be inlined by its tag, got %+v", p)
+	}
+	if p := props["Primary"]; p.Ref != "#/definitions/color" {
+		t.Errorf("expected Primary to reference color used twice, got %+v", p)
+	}
+	if p := props["Owner"]; p.Ref != "#/definitions/customer" {
+		t.Errorf("expected Owner to reference customer by its tag, got %+v", p)
+	}
+	if p := swag.Definitions["customer"].Properties["Address"]; p.Ref != "" || len(p.Properties) != 2 {
+		t.Errorf("expected Address used once to be inlined, got %+v", p)
+	}
+	for _, name := range []string{"coordinates", "dimensions", "address"} {
+		if _, ok := swag.Definitions[name]; ok {
+			t.Errorf("expected inlined definition %s to be removed", name)
+		}
+	}
+	for _, name := range []string{"store", "color", "customer"} {
+		if _, ok := swag.Definitions[name]; !ok {
+			t.Errorf("expected referenced definition %s to be kept", name)
+		}
+	}
+}
+
+type signup struct {
+	Username string   `json:"username" swagger:"minLength:3;maxLength:50;pattern:^[a-z]+:[0-9]+$"`
+	Age      int      `json:"age" swagger:"minimum:18;maximum:130;exclusiveMaximum:true"`
+	Tags     []string `json:"tags" swagger:"minItems:1;maxItems:5;uniqueItems:true;maxLength:10"`
+	Page     int      `json:"page" swagger:"in:query;minimum:1;multipleOf:1"`
+}
+
+func TestSwaggerizeConstraints(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/signup", Verb: "post", Model: signup{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["signup"].Properties
+	if p := props["username"]; p.MinLength != 3 || p.MaxLength != 50 || p.Pattern != "^[a-z]+:[0-9]+$" {
+		t.Errorf("expected username to be constrained, got %+v", p)
+	}
+	if p := props["age"]; p.Minimum == nil || *p.Minimum != 18 || p.Maximum == nil || *p.Maximum != 130 || !p.ExclusiveMaximum {
+		t.Errorf("expected age to be bounded, got %+v", p)
+	}
+	if p := props["tags"]; p.MinItems != 1 || p.MaxItems != 5 || !p.UniqueItems || p.MaxLength != 0 || p.Items.MaxLength != 10 {
+		t.Errorf("expected tags to be constrained, got %+v", p)
+	}
+
+	params := swag.Paths["/signup"].Post.Parameters
+	if param := params[len(params)-1]; param.Name != "page" || param.Minimum == nil || *param.Minimum != 1 || param.MultipleOf != 1 {
+		t.Errorf("expected page parameter to be constrained, got %+v", param)
+	}
+}
+
+type register struct {
+	Name     string   `json:"name,omitempty" validate:"required,min=3,max=50"`
+	Email    string   `json:"email" validate:"omitempty,email"`
+	Age      int      `json:"age" binding:"gte=18,lt=130"`
+	Role     string   `json:"role" validate:"oneof=admin user guest"`
+	Nickname string   `json:"nickname" validate:"alphanum,max=20" swagger:"maxLength:10"`
+	Emails   []string `json:"emails" validate:"min=1,dive,email"`
+}
+
+func TestSwaggerizeValidationTags(t *testing.T) {
+	generator := NewGenerator()
+	generator.ValidationTags = true
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{{Route: "/register", Verb: "post", Model: register{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := swag.Definitions["register"]
+	if !reflect.DeepEqual(definition.Required, []string{"name", "age", "role", "nickname", "emails"}) {
+		t.Errorf("unexpected required properties %v", definition.Required)
+	}
+	props := definition.Properties
+	if p := props["name"]; p.MinLength != 3 || p.MaxLength != 50 {
+		t.Errorf("expected name length to be bounded, got %+v", p)
+	}
+	if p := props["email"]; p.Format != "email" {
+		t.Errorf("expected email format, got %+v", p)
+	}
+	if p := props["age"]; p.Minimum == nil || *p.Minimum != 18 || p.Maximum == nil || *p.Maximum != 130 || !p.ExclusiveMaximum {
+		t.Errorf("expected age to be bounded, got %+v", p)
+	}
+	if p := props["role"]; !reflect.DeepEqual(p.Enum, []interface{}{"admin", "user", "guest"}) {
+		t.Errorf("expected role enum, got %+v", p)
+	}
+	if p := props["nickname"]; p.MaxLength != 10 || p.Pattern != "^[a-zA-Z0-9]+$" {
+		t.Errorf("expected the swagger tag to take precedence, got %+v", p)
+	}
+	if p := props["emails"]; p.MinItems != 1 || p.Items.Format != "" {
+		t.Errorf("expected rules after dive to be ignored, got %+v", p)
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	if _, err := Swaggerize(swag, []Route{{Route: "/register", Verb: "post", Model: register{}}}); err != nil {
+		t.Fatal(err)
+	}
+	if p := swag.Definitions["register"].Properties["name"]; p.MinLength != 0 {
+		t.Errorf("expected validation tags to be ignored by default, got %+v", p)
+	}
+}
+
+type quotedTags struct {
+	Status  []string `json:"status" swagger:" in : query ; required ; enum : ['in, stock', \"back;ordered\", sold\\,out, 'it\\'s'] "`
+	Pattern string   `json:"pattern" swagger:"pattern:'^a;b:c$';maxLength: 8"`
+	Escaped string   `json:"escaped" swagger:"pattern:^\\d+\\;$"`
+}
+
+func TestSwaggerizeTagGrammar(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	// GET requests have no body by default, the response documents the properties of quotedTags
+	_, err := Swaggerize(swag, []Route{{Route: "/items", Verb: "get", Model: quotedTags{}, Responses: []Response{
+		{Name: "200", Model: quotedTags{}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param := swag.Paths["/items"].Get.Parameters[0]
+	if param.In != "query" || !param.Required || !reflect.DeepEqual(param.Items.Enum, []interface{}{"in, stock", "back;ordered", "sold,out", "it's"}) {
+		t.Errorf("unexpected status parameter %+v", param)
+	}
+	props := swag.Definitions["quotedTags"].Properties
+	if p := props["pattern"]; p.Pattern != "^a;b:c$" || p.MaxLength != 8 {
+		t.Errorf("unexpected pattern property %+v", p)
+	}
+	if p := props["escaped"]; p.Pattern != `^\d+;$` {
+		t.Errorf("unexpected escaped property %+v", p)
+	}
+}
+
+type malformedTag struct {
+	Name   string `json:"name"`
+	Status string `json:"status" swagger:"enum:[a"`
+}
+
+type malformedEnum struct {
+	Code int `json:"code" swagger:"enum:[200, 'ok']"`
+}
+
+type embeddingMalformed struct {
+	malformedTag
+	ID string `json:"id"`
+}
+
+func TestSwaggerizeTagErrors(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/items", Verb: "post", Model: malformedTag{}}})
+	if err == nil || !strings.Contains(err.Error(), `POST /items: invalid swagger tag on swaggerizer.malformedTag.Status: key "enum"`) {
+		t.Errorf("expected the error to name the route, struct, field and key, got %v", err)
+	}
+	routeErr, ok := err.(*RouteError)
+	if !ok || routeErr.Verb != "post" || routeErr.Route != "/items" {
+		t.Fatalf("expected a *RouteError, got %#v", err)
+	}
+	if tagErr, ok := routeErr.Err.(*TagError); !ok || tagErr.Field != "Status" || tagErr.Key != "enum" {
+		t.Errorf("expected the *RouteError to wrap a *TagError, got %#v", routeErr.Err)
+	}
+	if routeErr.Unwrap() != routeErr.Err {
+		t.Errorf("expected Unwrap to return the *TagError")
+	}
+
+	_, err = Swaggerize(swag, []Route{{Route: "/items", Verb: "get", Responses: []Response{{Name: "200", Model: malformedTag{}}}}})
+	if routeErr, ok := err.(*RouteError); !ok || routeErr.Response != "200" {
+		t.Errorf("expected a *RouteError naming the response, got %#v", err)
+	} else if _, ok := routeErr.Err.(*TagError); !ok {
+		t.Errorf("expected the *RouteError to wrap a *TagError, got %#v", routeErr.Err)
+	}
+
+	// errors in promoted fields name the embedded struct declaring the field
+	for _, test := range []struct {
+		body     BodyMode
+		expected string
+	}{
+		{BodyDefault, "swaggerizer.malformedTag.Status"},
+		{BodyOnly, "swaggerizer.malformedTag.Status"},
+	} {
+		_, err = Swaggerize(swag, []Route{{Route: "/items", Verb: "post", Body: test.body, Model: embeddingMalformed{}}})
+		if routeErr, ok := err.(*RouteError); !ok {
+			t.Errorf("body mode %q: expected a *RouteError, got %#v", test.body, err)
+		} else if tagErr, ok := routeErr.Err.(*TagError); !ok || tagErr.Struct+"."+tagErr.Field != test.expected {
+			t.Errorf("body mode %q: expected a *TagError on %s, got %v", test.body, test.expected, routeErr.Err)
+		}
+	}
+	_, err = Swaggerize(swag, []Route{{Route: "/items", Verb: "get", Responses: []Response{{Name: "200", Model: struct {
+		malformedEnum
+	}{}}}}})
+	if err == nil || !strings.Contains(err.Error(), "invalid swagger tag on swaggerizer.malformedEnum.Code") {
+		t.Errorf("expected the error to name the embedded struct declaring the field, got %v", err)
+	}
+
+	for _, tag := range []string{
+		"enum:[a]]",
+		"enum:['a'",
+		"enum:a",
+		"enum",
+		"name",
+		"name:",
+		"required:yes",
+		"maxLength:-1",
+		"minimum:low",
+		"multipleOf:0",
+		"in:cookie",
+		"inline:sometimes",
+		"pattern:'^a",
+		"unknown:'a",
+		"required:true;required:false",
+		":true",
+		"in query",
+	} {
+		if _, err := parseParamsOptions(tag); err == nil {
+			t.Errorf("expected an error parsing %q", tag)
+		} else if _, ok := err.(*TagError); !ok {
+			t.Errorf("expected a *TagError parsing %q, got %T", tag, err)
+		}
+	}
+}
+
+type legacyTag struct {
+	Name  string `json:"name" swagger:"in:formdata;required;summary:'The name; trimmed'"`
+	Notes string `json:"notes" swagger:"readOnly;xml:[a, b];maxLength:10"`
+}
+
+func TestSwaggerizeUnknownTagKeys(t *testing.T) {
+	warnings := []string{}
+	generator := NewGenerator()
+	generator.Warn = func(warning string) {
+		warnings = append(warnings, warning)
+	}
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{
+		{Route: "/legacy", Verb: "post", Model: legacyTag{}},
+		{Route: "/legacy", Verb: "put", Model: legacyTag{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param, ok := findParameter(swag.Paths["/legacy"].Post.Parameters, "name")
+	if !ok || param.In != "formData" || !param.Required {
+		t.Errorf("expected the in value to be matched case-insensitively, got %+v", param)
+	}
+	if p := swag.Definitions["legacyTag"].Properties["notes"]; p.MaxLength != 10 {
+		t.Errorf("expected the known keys to be applied, got %+v", p)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Name.summary, Notes.readOnly, Notes.xml") {
+		t.Errorf("expected a single warning listing the unknown keys, got %v", warnings)
+	}
+}
+
+type annotatedOrder struct {
+	ID       int64             `json:"id,string" swagger:"description:The order id; example:42"`
+	Quantity int               `json:"quantity" swagger:"title:Quantity;example:3;default:1;minimum:1"`
+	Price    float64           `json:"price" swagger:"example:9.99"`
+	Gift     bool              `json:"gift" swagger:"default:false;deprecated"`
+	Tags     []string          `json:"tags" swagger:"example:'[\"fragile\", \"express\"]';format:slug"`
+	Extra    map[string]string `json:"extra" swagger:"example:{\"color\": \"red\"}"`
+	Page     uint64            `json:"page" swagger:"in:query;description:'Page number, starting at 1';default:1;example:2;deprecated:true"`
+}
+
+func TestSwaggerizeAnnotations(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/orders", Verb: "post", Model: annotatedOrder{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["annotatedOrder"].Properties
+	if p := props["id"]; p.Description != "The order id" || p.Example != "42" {
+		t.Errorf("expected a string example for a string encoded id, got %+v", p)
+	}
+	if p := props["quantity"]; p.Title != "Quantity" || p.Example != int64(3) || p.Default != int64(1) {
+		t.Errorf("expected integer example and default, got %+v", p)
+	}
+	if p := props["price"]; p.Example != 9.99 {
+		t.Errorf("expected a number example, got %+v", p)
+	}
+	if p := props["gift"]; p.Default != false || !p.Deprecated {
+		t.Errorf("expected a boolean default and deprecation, got %+v", p)
+	}
+	if p := props["tags"]; !reflect.DeepEqual(p.Example, []interface{}{"fragile", "express"}) || p.Items.Format != "slug" {
+		t.Errorf("expected an array example and items format, got %+v", p)
+	}
+	if p := props["extra"]; !reflect.DeepEqual(p.Example, map[string]interface{}{"color": "red"}) {
+		t.Errorf("expected an object example, got %+v", p)
+	}
+
+	params := swag.Paths["/orders"].Post.Parameters
+	param := params[len(params)-1]
+	if param.Description != "Page number, starting at 1" || param.Default != int64(1) || param.Example != int64(2) || !param.Deprecated {
+		t.Errorf("expected the page parameter to be annotated, got %+v", param)
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	_, err = Swaggerize(swag, []Route{{Route: "/orders", Verb: "post", Model: invalidExample{}}})
+	if err == nil || !strings.Contains(err.Error(), `swaggerizer.invalidExample.Count: key "example": expected an integer`) {
+		t.Errorf("expected an example that does not match the field type to be reported, got %v", err)
+	}
+}
+
+type invalidExample struct {
+	Count int `json:"count" swagger:"example:many"`
+}
+
+type priced struct {
+	Price     money `json:"price" swagger:"example:'10 EUR'"`
+	Discount  money `json:"discount" swagger:"description:Discount granted"`
+	Undecided money `json:"undecided" swagger:"deprecated:false"`
+}
+
+func TestSwaggerizeAnnotationsKeepRegisteredSchemas(t *testing.T) {
+	generator := NewGenerator().RegisterType(reflect.TypeOf(money{}), swagger.Schema{
+		Type:        "string",
+		Title:       "Money",
+		Description: "An amount and its currency",
+		Deprecated:  true,
+	})
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{{Route: "/prices", Verb: "post", Model: priced{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["priced"].Properties
+	if p := props["price"]; p.Description != "An amount and its currency" || p.Title != "Money" || !p.Deprecated || p.Example != "10 EUR" {
+		t.Errorf("expected the registered annotations to be kept, got %+v", p)
+	}
+	if p := props["discount"]; p.Description != "Discount granted" || p.Title != "Money" {
+		t.Errorf("expected only the description to be overridden, got %+v", p)
+	}
+	if p := props["undecided"]; p.Deprecated || p.Description != "An amount and its currency" {
+		t.Errorf("expected only the deprecation to be overridden, got %+v", p)
+	}
+}
+
+type typedEnums struct {
+	Code     int       `json:"code" swagger:"enum:[200, 404, 500]"`
+	Ratio    float32   `json:"ratio" swagger:"enum:[0.5, 1]"`
+	Enabled  bool      `json:"enabled" swagger:"enum:[true]"`
+	Levels   []int8    `json:"levels" swagger:"in:query;enum:[1,2,3]"`
+	Priority int       `json:"priority,string" swagger:"enum:['1','2']"`
+	Matrix   [][]int16 `json:"matrix" swagger:"enum:[0,255]"`
+}
+
+func TestSwaggerizeTypedEnums(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	// the response documents every field of typedEnums, levels included, while the request sends it as a parameter
+	_, err := Swaggerize(swag, []Route{{Route: "/enums", Verb: "post", Model: typedEnums{}, Responses: []Response{
+		{Name: "200", Model: typedEnums{}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := swag.Definitions["typedEnums"].Properties
+	if p := props["code"]; !reflect.DeepEqual(p.Enum, []interface{}{int64(200), int64(404), int64(500)}) {
+		t.Errorf("expected an integer enum, got %#v", p.Enum)
+	}
+	if p := props["ratio"]; !reflect.DeepEqual(p.Enum, []interface{}{0.5, 1.0}) {
+		t.Errorf("expected a number enum, got %#v", p.Enum)
+	}
+	if p := props["enabled"]; !reflect.DeepEqual(p.Enum, []interface{}{true}) {
+		t.Errorf("expected a boolean enum, got %#v", p.Enum)
+	}
+	if p := props["levels"]; p.Enum != nil || !reflect.DeepEqual(p.Items.Enum, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Errorf("expected the enum to apply to the items, got %+v", p)
+	}
+	if p := props["priority"]; !reflect.DeepEqual(p.Enum, []interface{}{"1", "2"}) {
+		t.Errorf("expected a string enum for a string encoded integer, got %#v", p.Enum)
+	}
+	if p := props["matrix"]; p.Items.Enum != nil || !reflect.DeepEqual(p.Items.Items.Enum, []interface{}{int64(0), int64(255)}) {
+		t.Errorf("expected the enum to apply to the innermost items, got %+v", p)
+	}
+
+	param, ok := findParameter(swag.Paths["/enums"].Post.Parameters, "levels")
+	if !ok || param.Enum != nil || !reflect.DeepEqual(param.Items.Enum, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Errorf("expected the parameter enum to apply to the items, got %+v", param)
+	}
+
+	out, err := json.Marshal(props["code"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"enum":[200,404,500]`) {
+		t.Errorf("expected enum values to be serialized as numbers, got %s", out)
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	_, err = Swaggerize(swag, []Route{{Route: "/enums", Verb: "post", Model: invalidEnum{}}})
+	if err == nil || !strings.Contains(err.Error(), `swaggerizer.invalidEnum.Code: key "enum": expected an integer, got "ok"`) {
+		t.Errorf("expected an enum value that does not match the field type to be reported, got %v", err)
+	}
+}
+
+type invalidEnum struct {
+	Code int `json:"code" swagger:"enum:[200, ok]"`
+}
+
+type deleteUser struct {
+	Username string `json:"username" swagger:"required;in:path"`
+	Reason   string `json:"reason" swagger:"in:query"`
+}
+
+type replaceCustomer struct {
+	ID       string   `json:"id" swagger:"required;in:path"`
+	Customer customer `json:"customer" swagger:"in:body;description:The new customer"`
+}
+
+type twoBodies struct {
+	Customer customer `swagger:"in:body"`
+	Address  address  `swagger:"in:body"`
+}
+
+type bodyAndProperties struct {
+	Customer customer `swagger:"in:body"`
+	Note     string
+}
+
+func TestSwaggerizeParameterFields(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Route: "/user/{username}", Verb: "put", Model: putUser{}},
+		{Route: "/user/{username}", Verb: "delete", Model: deleteUser{}},
+		{Route: "/customer/{id}", Verb: "put", Model: replaceCustomer{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := swag.Definitions["putUser"]
+	if _, ok := definition.Properties["username"]; ok || !reflect.DeepEqual(definition.Required, []string{"Email"}) {
+		t.Errorf("expected the path parameter to be excluded from the body definition, got %+v", definition)
+	}
+	params := swag.Paths["/user/{username}"].Put.Parameters
+	if len(params) != 2 || params[0].In != "body" || params[1].In != "path" {
+		t.Errorf("expected a body and a path parameter, got %+v", params)
+	}
+
+	params = swag.Paths["/user/{username}"].Delete.Parameters
+	if len(params) != 2 || params[0].In != "path" || params[1].In != "query" {
+		t.Errorf("expected no body parameter for a model made of parameters, got %+v", params)
+	}
+	if _, ok := swag.Definitions["deleteUser"]; ok {
+		t.Errorf("expected no definition for a model made of parameters")
+	}
+
+	params = swag.Paths["/customer/{id}"].Put.Parameters
+	if len(params) != 2 || params[0].In != "body" || params[0].Schema.Ref != "#/definitions/customer" || params[1].Name != "id" {
+		t.Errorf("expected the in:body field to be the body, got %+v", params)
+	}
+	if _, ok := swag.Definitions["replaceCustomer"]; ok {
+		t.Errorf("expected no definition for the model wrapping the body")
+	}
+
+	for _, model := range []interface{}{twoBodies{}, bodyAndProperties{}} {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		if _, err := Swaggerize(swag, []Route{{Route: "/customer", Verb: "post", Model: model}}); err == nil {
+			t.Errorf("expected an error for %T", model)
+		}
+	}
+}
+
+type userPage struct {
+	Users []putUser `json:"users"`
+}
+
+func TestSwaggerizeParameterFieldsElsewhere(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Route: "/user/{username}", Verb: "put", Model: putUser{}},
+		{Route: "/user/{username}", Verb: "get", Model: getUser{}, Responses: []Response{{Name: "200", Model: putUser{}}}},
+		{Route: "/users", Verb: "get", Responses: []Response{{Name: "200", Model: userPage{}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := swag.Definitions["putUser"]
+	if _, ok := definition.Properties["username"]; !ok {
+		t.Errorf("expected the response and nested uses of putUser to document every field, got %+v", definition)
+	}
+	if p := swag.Definitions["userPage"].Properties["users"]; p.Items == nil || p.Items.Ref != "#/definitions/putUser" {
+		t.Errorf("expected users to reference putUser, got %+v", p)
+	}
+	body, ok := findParameter(swag.Paths["/user/{username}"].Put.Parameters, "body")
+	if !ok || body.Schema.Ref != "" || len(body.Schema.Properties) != 1 || body.Schema.Properties["Email"].Type != "string" {
+		t.Errorf("expected the request body of putUser to be inlined without its parameters, got %+v", body.Schema)
+	}
+}
+
+func TestSwaggerizeParameterModelNames(t *testing.T) {
+	// License shares its short name with swagger.License, but has no body definition to name
+	type License struct {
+		Key string `json:"key" swagger:"in:query"`
+	}
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/license", Verb: "get", Model: License{}, Responses: []Response{
+		{Name: "200", Model: swagger.License{}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := swag.Definitions["License"].Properties["name"]; !ok || len(swag.Definitions) != 1 {
+		t.Errorf("expected swagger.License to keep its short name, got %+v", swag.Definitions)
+	}
+}
+
+func TestSwaggerizeBodyModes(t *testing.T) {
+	cases := []struct {
+		verb     string
+		body     BodyMode
+		model    interface{}
+		hasBody  bool
+		params   int
+		warnings int
+	}{
+		{"put", BodyDefault, putUser{}, true, 1, 0},
+		{"delete", BodyDefault, getUser{}, false, 1, 0},
+		{"delete", BodyDefault, putUser{}, false, 1, 1},
+		{"get", BodyAndParameters, putUser{}, true, 1, 1},
+		// with BodyOnly, the only parameter is the {username} variable of the path template, documented automatically
+		{"post", BodyOnly, putUser{}, true, 1, 1},
+		{"post", BodyOnly, getUser{}, true, 1, 1},
+		{"post", ParametersOnly, putUser{}, false, 1, 1},
+		{"post", BodyAndParameters, getUser{}, false, 1, 1},
+	}
+	for _, c := range cases {
+		warnings := []string{}
+		generator := NewGenerator()
+		generator.Warn = func(warning string) {
+			warnings = append(warnings, warning)
+		}
+
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		route := Route{Route: "/user/{username}", Verb: c.verb, Body: c.body, Model: c.model}
+		if _, err := generator.Swaggerize(swag, []Route{route}); err != nil {
+			t.Fatal(err)
+		}
+
+		var operation *swagger.PathItem
+		methods := swag.Paths["/user/{username}"]
+		switch c.verb {
+		case "get":
+			operation = methods.Get
+		case "post":
+			operation = methods.Post
+		case "put":
+			operation = methods.Put
+		case "delete":
+			operation = methods.Delete
+		}
+		hasBody := false
+		params := 0
+		for _, param := range operation.Parameters {
+			if param.In == "body" {
+				hasBody = true
+			} else {
+				params++
+			}
+		}
+		if hasBody != c.hasBody || params != c.params || len(warnings) != c.warnings {
+			t.Errorf("%s %T with body mode %q: expected body %t, %d parameters and %d warnings, got %t, %d and %v",
+				c.verb, c.model, c.body, c.hasBody, c.params, c.warnings, hasBody, params, warnings)
+		}
+		if c.body == BodyOnly {
+			if param, ok := findParameter(operation.Parameters, "username"); !ok || param.In != "path" || param.Type != "string" || !param.Required {
+				t.Errorf("%s %T with body mode %q: expected only the documented path variable as a parameter, got %+v",
+					c.verb, c.model, c.body, operation.Parameters)
+			}
+		}
+	}
+
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/user", Verb: "post", Body: "form", Model: putUser{}}})
+	if err == nil || !strings.Contains(err.Error(), `unknown body mode "form"`) {
+		t.Errorf("expected an unknown body mode to be rejected, got %v", err)
+	}
+}
+
+func TestSwaggerizeBodyOnly(t *testing.T) {
+	generator := NewGenerator()
+	generator.Warn = func(string) {}
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{
+		{Route: "/user/{username}", Verb: "post", Body: BodyOnly, Model: putUser{}},
+		{Route: "/lookup", Verb: "post", Body: BodyOnly, Model: getUser{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"putUser", "getUser"} {
+		if _, ok := swag.Definitions[name].Properties["username"]; !ok {
+			t.Errorf("expected the fields tagged with in to be properties of the %s body, got %+v", name, swag.Definitions[name])
+		}
+	}
+	if body, ok := findParameter(swag.Paths["/lookup"].Post.Parameters, "body"); !ok || body.Schema.Ref != "#/definitions/getUser" {
+		t.Errorf("expected a body for a model made of parameters, got %+v", swag.Paths["/lookup"].Post.Parameters)
+	}
+}
+
+func TestSwaggerizeIgnoredBodyDefinitions(t *testing.T) {
+	generator := NewGenerator()
+	generator.Warn = func(string) {}
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := generator.Swaggerize(swag, []Route{
+		{Route: "/user/{username}", Verb: "get", Model: putUser{}},
+		{Route: "/customer/{id}", Verb: "delete", Model: replaceCustomer{}},
+		{Route: "/store", Verb: "post", Body: ParametersOnly, Model: store{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swag.Definitions) != 0 {
+		t.Errorf("expected no definitions for bodies that are not documented, got %+v", swag.Definitions)
+	}
+	if params := swag.Paths["/user/{username}"].Get.Parameters; len(params) != 1 || params[0].In != "path" {
+		t.Errorf("expected only the path parameter, got %+v", params)
+	}
+}
+
+func TestSwaggerizeVerbs(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Route: "/user/{username}", Verb: "PATCH", Model: putUser{}},
+		{Route: "/user/{username}", Verb: "head", Model: getUser{}},
+		{Route: "/user/{username}", Verb: "options"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	methods := swag.Paths["/user/{username}"]
+	if methods.Patch == nil || methods.Head == nil || methods.Options == nil {
+		t.Fatalf("expected patch, head and options operations, got %+v", methods)
+	}
+	if params := methods.Patch.Parameters; len(params) != 2 || params[0].In != "body" {
+		t.Errorf("expected a body on the patch operation, got %+v", params)
+	}
+	if params := methods.Head.Parameters; len(params) != 1 || params[0].In != "path" {
+		t.Errorf("expected only a path parameter on the head operation, got %+v", params)
+	}
+
+	out, err := json.Marshal(methods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, verb := range []string{`"patch"`, `"head"`, `"options"`} {
+		if !strings.Contains(string(out), verb) {
+			t.Errorf("expected %s to be serialized, got %s", verb, out)
+		}
+	}
+
+	swag = swagger.NewSwagger("myapi.example.com", "/")
+	_, err = Swaggerize(swag, []Route{{Route: "/user", Verb: "purge", Model: getUser{}}})
+	if err == nil || !strings.Contains(err.Error(), `unsupported verb "purge"`) {
+		t.Errorf("expected an unknown verb to be rejected, got %v", err)
+	}
+}
+
+func TestSwaggerizeDuplicateRoutes(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{
+		{Group: "user", Route: "/user/{username}", Verb: "get", Model: getUser{}},
+		{Group: "user", Route: "/user/{username}", Verb: "GET", Model: getUser{}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "GET /user/{username}: path /user/{username}: operation get is already defined") {
+		t.Errorf("expected a duplicate route to be rejected, got %v", err)
+	}
+	if _, ok := err.(*RouteError); !ok {
+		t.Errorf("expected a *RouteError, got %T", err)
+	}
+	if len(swag.Paths) != 0 || len(swag.Tags) != 0 || len(swag.Definitions) != 0 {
+		t.Errorf("expected the model to be left unchanged, got %+v", swag)
+	}
+}
+
+type userOrder struct {
+	OrderID int64 `json:"orderId" swagger:"required;in:path"`
+}
+
+type optionalPathParameter struct {
+	Username string `json:"username" swagger:"in:path"`
+}
+
+func TestSwaggerizePathTemplates(t *testing.T) {
+	swag := swagger.NewSwagger("myapi.example.com", "/")
+	_, err := Swaggerize(swag, []Route{{Route: "/user/{username}/orders/{orderId}", Verb: "get", Model: userOrder{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := swag.Paths["/user/{username}/orders/{orderId}"].Get.Parameters
+	expected := []swagger.PathItemParameter{
+		{In: "path", Name: "orderId", Required: true, Type: "integer", Format: "int64"},
+		{In: "path", Name: "username", Required: true, Type: "string"},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected the username variable to be documented, got %+v", params)
+	}
+
+	cases := []struct {
+		route string
+		model interface{}
+		err   string
+	}{
+		{"/user", getUser{}, "path parameter username has no {username} variable in the path template"},
+		{"/user/{name}", getUser{}, "path parameter username has no {username} variable"},
+		{"/user/{username}", optionalPathParameter{}, "path parameter username must be required"},
+		{"/user/{username", getUser{}, "unterminated '{'"},
+		{"/user/{user{name}}", getUser{}, "unterminated '{'"},
+		{"/user/username}", getUser{}, "unmatched '}'"},
+		{"/user/{}", nil, "empty variable"},
+		{"/user/{username}/{username}", getUser{}, "declares {username} twice"},
+	}
+	for _, c := range cases {
+		swag := swagger.NewSwagger("myapi.example.com", "/")
+		_, err := Swaggerize(swag, []Route{{Route: c.route, Verb: "get", Model: c.model}})
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.route, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package swaggerizer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
+
+// TagError reports a swagger tag that could not be parsed.
+type TagError struct {
+	// Struct is the type declaring the field
+	Struct string
+	// Field is the Go name of the field
+	Field string
+	// Key is the offending key, empty if the error is not specific to a key
+	Key string
+	Err error
+}
+
+func (e *TagError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("invalid swagger tag on %s.%s: %v", e.Struct, e.Field, e.Err)
+	}
+	return fmt.Sprintf("invalid swagger tag on %s.%s: key %q: %v", e.Struct, e.Field, e.Key, e.Err)
+}
+
+// tagKind is the type of the value of a swagger tag key.
+type tagKind int
+
+const (
+	tagString tagKind = iota
+	tagBool
+	tagInt
+	tagNumber
+	tagList
+)
+
+var tagKeys = map[string]tagKind{
+	"required":         tagBool,
+	"in":               tagString,
+	"multiple":         tagBool,
+	"enum":             tagList,
+	"allOf":            tagBool,
+	"inline":           tagString,
+	"name":             tagString,
+	"maximum":          tagNumber,
+	"exclusiveMaximum": tagBool,
+	"minimum":          tagNumber,
+	"exclusiveMinimum": tagBool,
+	"maxLength":        tagInt,
+	"minLength":        tagInt,
+	"pattern":          tagString,
+	"maxItems":         tagInt,
+	"minItems":         tagInt,
+	"uniqueItems":      tagBool,
+	"multipleOf":       tagNumber,
+	"format":           tagString,
+	"description":      tagString,
+	"title":            tagString,
+	"example":          tagString,
+	"default":          tagString,
+	"deprecated":       tagBool,
+}
+
+var parameterLocations = []string{"query", "header", "path", "formData", "body"}
+
+// tagEntry is a key of a swagger tag along with its value.
+type tagEntry struct {
+	key   string
+	value string
+	list  []string
+	// bare is set on keys given without a value, such as `required`
+	bare bool
+}
+
+// tagParser splits a swagger tag into entries. The grammar is
+//
+//	tag   = entry { ";" entry }
+//	entry = key [ ":" value ]
+//	value = quoted | list | text
+//	list  = "[" [ (quoted | text) { "," (quoted | text) } ] "]"
+//
+// Quoted values are enclosed in single or double quotes. A backslash escapes the quotes and the
+// separators ; , and ] in any value, other backslashes are kept as is so patterns read naturally.
+// Whitespace around keys, values and separators is ignored.
+type tagParser struct {
+	tag string
+	pos int
+	key string
+	// unknown holds the unknown keys, which are skipped along with their value
+	unknown []string
+}
+
+func (p *tagParser) errorf(format string, args ...interface{}) error {
+	return &TagError{Key: p.key, Err: fmt.Errorf(format, args...)}
+}
+
+func (p *tagParser) skipSpaces() {
+	for p.pos < len(p.tag) && unicode.IsSpace(rune(p.tag[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *tagParser) done() bool {
+	return p.pos >= len(p.tag)
+}
+
+func (p *tagParser) parse() ([]tagEntry, error) {
+	entries := []tagEntry{}
+	seen := make(map[string]bool)
+	for {
+		p.key = ""
+		p.skipSpaces()
+		if p.done() {
+			return entries, nil
+		}
+		if p.tag[p.pos] == ';' {
+			p.pos++
+			continue
+		}
+
+		start := p.pos
+		for !p.done() && strings.IndexByte(":; \t\n", p.tag[p.pos]) < 0 {
+			p.pos++
+		}
+		p.key = p.tag[start:p.pos]
+		if p.key == "" {
+			return nil, p.errorf("missing key at offset %d", start)
+		}
+		kind, known := tagKeys[p.key]
+		if !known {
+			// unknown keys are parsed so that the tag remains valid, then skipped
+			p.unknown = append(p.unknown, p.key)
+		} else if seen[p.key] {
+			return nil, p.errorf("duplicate key")
+		}
+		seen[p.key] = true
+
+		entry := tagEntry{key: p.key}
+		p.skipSpaces()
+		if p.done() || p.tag[p.pos] == ';' {
+			entry.bare = true
+			if known {
+				entries = append(entries, entry)
+			}
+			continue
+		}
+		if p.tag[p.pos] != ':' {
+			return nil, p.errorf("expected ':' after key, got %q", p.tag[p.pos])
+		}
+		p.pos++
+		p.skipSpaces()
+
+		var err error
+		if kind == tagList || !known && !p.done() && p.tag[p.pos] == '[' {
+			entry.list, err = p.parseList()
+		} else {
+			entry.value, err = p.parseValue(";")
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.done() && p.tag[p.pos] != ';' {
+			return nil, p.errorf("unexpected %q after value", p.tag[p.pos])
+		}
+		if known {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// parseList parses a bracketed list of values.
+func (p *tagParser) parseList() ([]string, error) {
+	if p.done() || p.tag[p.pos] != '[' {
+		return nil, p.errorf("expected a list such as ['a','b']")
+	}
+	p.pos++
+	values := []string{}
+	p.skipSpaces()
+	if !p.done() && p.tag[p.pos] == ']' {
+		p.pos++
+		return values, nil
+	}
+	for {
+		p.skipSpaces()
+		value, err := p.parseValue(",]")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		p.skipSpaces()
+		if p.done() {
+			return nil, p.errorf("unterminated list")
+		}
+		switch p.tag[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return values, nil
+		default:
+			return nil, p.errorf("expected ',' or ']' in list, got %q", p.tag[p.pos])
+		}
+	}
+}
+
+// parseValue parses a quoted value, or an unquoted value ending before any of the stop characters.
+func (p *tagParser) parseValue(stop string) (string, error) {
+	if !p.done() && (p.tag[p.pos] == '\'' || p.tag[p.pos] == '"') {
+		quote := p.tag[p.pos]
+		p.pos++
+		value := []byte{}
+		for !p.done() {
+			c := p.tag[p.pos]
+			p.pos++
+			if c == quote {
+				return string(value), nil
+			}
+			if c == '\\' && !p.done() && isTagEscape(p.tag[p.pos]) {
+				c = p.tag[p.pos]
+				p.pos++
+			}
+			value = append(value, c)
+		}
+		return "", p.errorf("unterminated quoted value")
+	}
+
+	value := []byte{}
+	for !p.done() && strings.IndexByte(stop, p.tag[p.pos]) < 0 {
+		c := p.tag[p.pos]
+		p.pos++
+		if c == '\\' && !p.done() && isTagEscape(p.tag[p.pos]) {
+			c = p.tag[p.pos]
+			p.pos++
+		}
+		value = append(value, c)
+	}
+	return strings.TrimRightFunc(string(value), unicode.IsSpace), nil
+}
+
+func isTagEscape(c byte) bool {
+	return strings.IndexByte(`'";,]`, c) >= 0
+}
+
+// parseTagOptions parses the swagger tag of a field declared on the struct type t.
+// Errors are returned as a *TagError naming the struct, field and key.
+func parseTagOptions(t reflect.Type, field reflect.StructField) (*options, error) {
+	ret, err := parseParamsOptions(field.Tag.Get("swagger"))
+	return ret, fieldTagError(t, field, err)
+}
+
+// fieldTagError names the struct type t and its field in err if it is a *TagError.
+func fieldTagError(t reflect.Type, field reflect.StructField, err error) error {
+	if tagErr, ok := err.(*TagError); ok {
+		tagErr.Struct = t.String()
+		tagErr.Field = field.Name
+	}
+	return err
+}
+
+// parseParamsOptions parses a swagger tag into options. A nil options is returned for an empty tag.
+// Unknown keys are ignored and listed in options.Unknown.
+func parseParamsOptions(tag string) (*options, error) {
+	if strings.TrimSpace(tag) == "" {
+		return nil, nil
+	}
+	parser := &tagParser{tag: tag}
+	entries, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &options{Unknown: parser.unknown}
+	for _, entry := range entries {
+		if err := ret.set(entry); err != nil {
+			return nil, &TagError{Key: entry.key, Err: err}
+		}
+	}
+	return ret, nil
+}
+
+// set applies a tag entry to o, converting its value to the type of the key.
+func (o *options) set(entry tagEntry) error {
+	kind := tagKeys[entry.key]
+	if entry.bare && kind != tagBool {
+		return errors.New("missing value")
+	}
+
+	var b bool
+	var i int
+	var f float64
+	var err error
+	switch kind {
+	case tagBool:
+		b = true
+		if !entry.bare {
+			b, err = strconv.ParseBool(entry.value)
+			if err != nil {
+				return fmt.Errorf("expected a boolean, got %q", entry.value)
+			}
+		}
+	case tagInt:
+		i, err = strconv.Atoi(entry.value)
+		if err != nil || i < 0 {
+			return fmt.Errorf("expected a non-negative integer, got %q", entry.value)
+		}
+	case tagNumber:
+		f, err = strconv.ParseFloat(entry.value, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", entry.value)
+		}
+	}
+
+	switch entry.key {
+	case "required":
+		o.Required = b
+		o.RequiredSet = true
+	case "in":
+		o.In = ""
+		for _, location := range parameterLocations {
+			if strings.EqualFold(location, entry.value) {
+				o.In = location
+			}
+		}
+		if o.In == "" {
+			return fmt.Errorf("expected one of %s, got %q", strings.Join(parameterLocations, ", "), entry.value)
+		}
+	case "multiple":
+		if b {
+			o.CollectionFormat = "multi"
+		}
+	case "enum":
+		o.Enum = entry.list
+	case "allOf":
+		o.AllOf = b
+	case "inline":
+		mode := InlineMode(entry.value)
+		if mode != InlineNever && mode != InlineAlways && mode != InlineOnce {
+			return fmt.Errorf("expected one of %s, %s, %s, got %q", InlineNever, InlineAlways, InlineOnce, entry.value)
+		}
+		o.Inline = mode
+	case "name":
+		if entry.value == "" {
+			return errors.New("missing value")
+		}
+		o.Name = entry.value
+	case "maximum":
+		o.Maximum = &f
+	case "exclusiveMaximum":
+		o.ExclusiveMaximum = b
+	case "minimum":
+		o.Minimum = &f
+	case "exclusiveMinimum":
+		o.ExclusiveMinimum = b
+	case "maxLength":
+		o.MaxLength = i
+	case "minLength":
+		o.MinLength = i
+	case "pattern":
+		o.Pattern = entry.value
+	case "maxItems":
+		o.MaxItems = i
+	case "minItems":
+		o.MinItems = i
+	case "uniqueItems":
+		o.UniqueItems = b
+	case "multipleOf":
+		if f <= 0 {
+			return fmt.Errorf("expected a number greater than 0, got %q", entry.value)
+		}
+		o.MultipleOf = f
+	case "format":
+		o.Format = entry.value
+	case "description":
+		o.Description = entry.value
+	case "title":
+		o.Title = entry.value
+	case "example":
+		o.Example = entry.value
+		o.ExampleSet = true
+	case "default":
+		o.Default = entry.value
+		o.DefaultSet = true
+	case "deprecated":
+		o.Deprecated = b
+		o.DeprecatedSet = true
+	}
+	return nil
+}
+
+// applyAnnotations sets the description, title, example, default and deprecation of o on prop, keeping
+// those of prop that o does not set, such as the description of a registered type.
+// Examples and defaults are converted to the JSON type of prop.
+func applyAnnotations(prop *swagger.DefinitionProperty, o *options) error {
+	if o.Description != "" {
+		prop.Description = o.Description
+	}
+	if o.Title != "" {
+		prop.Title = o.Title
+	}
+	if o.DeprecatedSet {
+		prop.Deprecated = o.Deprecated
+	}
+	if o.ExampleSet {
+		example, err := typedValue(*prop, o.Example)
+		if err != nil {
+			return &TagError{Key: "example", Err: err}
+		}
+		prop.Example = example
+	}
+	if o.DefaultSet {
+		value, err := typedValue(*prop, o.Default)
+		if err != nil {
+			return &TagError{Key: "default", Err: err}
+		}
+		prop.Default = value
+	}
+	return nil
+}
+
+// applyEnum sets the enum of o on prop, or on its items for arrays. Values are converted to the JSON
+// type of the schema they apply to.
+func applyEnum(prop *swagger.DefinitionProperty, o *options) error {
+	if o.Enum == nil {
+		return nil
+	}
+	values := valuesSchema(prop)
+	enum := make([]interface{}, 0, len(o.Enum))
+	for _, value := range o.Enum {
+		typed, err := typedValue(*values, value)
+		if err != nil {
+			return &TagError{Key: "enum", Err: err}
+		}
+		enum = append(enum, typed)
+	}
+	values.Enum = enum
+	return nil
+}
+
+// typedValue converts a tag value to the JSON type of prop. Values of arrays, objects and
+// references are parsed as JSON.
+func typedValue(prop swagger.DefinitionProperty, value string) (interface{}, error) {
+	switch prop.Type {
+	case "string":
+		return value, nil
+	case "boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a boolean, got %q", value)
+		}
+		return v, nil
+	case "integer":
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v, nil
+		}
+		return nil, fmt.Errorf("expected an integer, got %q", value)
+	case "number":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return v, nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("expected a JSON value, got %q", value)
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package swaggerizer
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/erikperez/go-swaggerize/pkg/swagger"
+)
+
+// SchemaProvider is implemented by types that describe their own schema, typically because they
+// implement a custom MarshalJSON. The returned schema is used as is instead of reflecting the type.
+// It is called on the zero value of the type, or on a pointer to a zero value for pointer receivers.
+type SchemaProvider interface {
+	SwaggerSchema() swagger.Schema
+}
+
+var schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+
+// providedSchema returns the schema of t if t implements SchemaProvider on its value or pointer receiver.
+func providedSchema(t reflect.Type) (swagger.Schema, bool) {
+	if !implements(t, schemaProviderType) {
+		return swagger.Schema{}, false
+	}
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(SchemaProvider).SwaggerSchema(), true
+	}
+	return reflect.New(t).Interface().(SchemaProvider).SwaggerSchema(), true
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// implements reports whether t or a pointer to t implements the interface type iface.
+// Pointer types are dereferenced by the caller, so they never implement iface themselves.
+// It is used for SchemaProvider, which may be implemented on either receiver.
+func implements(t reflect.Type, iface reflect.Type) bool {
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Ptr {
+		return false
+	}
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// wellKnownTypes maps standard library types to the schema of their JSON representation.
+// Kind based reflection would describe their Go layout instead.
+var wellKnownTypes = map[reflect.Type]swagger.Schema{
+	reflect.TypeOf(time.Time{}):       {Type: "string", Format: "date-time"},
+	reflect.TypeOf(time.Duration(0)):  {Type: "integer", Format: "int64"},
+	reflect.TypeOf(net.IP{}):          {Type: "string", Format: "ipv4"},
+	reflect.TypeOf(url.URL{}):         {Type: "string", Format: "uri"},
+	reflect.TypeOf(json.RawMessage{}): {Type: "object"},
+	reflect.TypeOf(json.Number("")):   {Type: "number"},
+	reflect.TypeOf([]byte{}):          {Type: "string", Format: "byte"},
+	reflect.TypeOf(big.Int{}):         {Type: "integer"}, // big.Int is marshaled as a JSON number
+	reflect.TypeOf(big.Float{}):       {Type: "string"},
+	reflect.TypeOf(big.Rat{}):         {Type: "string"},
+}
+
+// wellKnownType returns the schema of t if it is a well-known type.
+func wellKnownType(t reflect.Type) (swagger.Schema, bool) {
+	if prop, ok := wellKnownTypes[t]; ok {
+		return prop, true
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		// encoding/json encodes every byte slice as a base64 string
+		return swagger.Schema{Type: "string", Format: "byte"}, true
+	}
+	return swagger.Schema{}, false
+}
@@ -0,0 +1,206 @@
+package swaggerizer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validationTags are the struct tags of go-playground/validator and gin read when Generator.ValidationTags is set.
+var validationTags = []string{"validate", "binding"}
+
+// validationFormats maps validator tags to the format they validate.
+var validationFormats = map[string]string{
+	"email":    "email",
+	"uuid":     "uuid",
+	"uuid3":    "uuid",
+	"uuid4":    "uuid",
+	"uuid5":    "uuid",
+	"url":      "uri",
+	"uri":      "uri",
+	"datetime": "date-time",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+// validationPatterns maps validator tags to the pattern they validate.
+var validationPatterns = map[string]string{
+	"alpha":       "^[a-zA-Z]+$",
+	"alphanum":    "^[a-zA-Z0-9]+$",
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":      "^[0-9]+$",
+	"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+	"lowercase":   "^[^A-Z]*$",
+	"uppercase":   "^[^a-z]*$",
+	"e164":        "^\\+[1-9]?[0-9]{7,14}$",
+}
+
+// parseValidationTags translates the validate and binding tags of field into options. Constraints are mapped to
+// the keyword matching the kind of the field: min and max are lengths on strings, numbers of items on slices and
+// maps, and bounds on numbers. Validations that have no schema equivalent are ignored, as are the ones following
+// dive, which apply to the items of the field.
+func parseValidationTags(field reflect.StructField) *options {
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var ret *options
+	for _, name := range validationTags {
+		tag := field.Tag.Get(name)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if ret == nil {
+			ret = &options{}
+		}
+
+	rules:
+		for _, rule := range strings.Split(tag, ",") {
+			if strings.Contains(rule, "|") {
+				// alternatives cannot be expressed by a single schema
+				continue
+			}
+			splitRule := strings.SplitN(rule, "=", 2)
+			key := strings.TrimSpace(splitRule[0])
+			value := ""
+			if len(splitRule) == 2 {
+				value = strings.TrimSpace(splitRule[1])
+			}
+
+			switch key {
+			case "dive":
+				break rules
+			case "required":
+				ret.Required = true
+				ret.RequiredSet = true
+			case "omitempty":
+				if !ret.Required {
+					ret.RequiredSet = true
+				}
+			case "min", "max", "len":
+				applyValidationBound(ret, t, key, value)
+			case "gt", "gte", "lt", "lte":
+				applyValidationBound(ret, t, key, value)
+			case "oneof":
+				ret.Enum = strings.Fields(value)
+			case "unique":
+				ret.UniqueItems = true
+			default:
+				if format, ok := validationFormats[key]; ok {
+					ret.Format = format
+				} else if pattern, ok := validationPatterns[key]; ok {
+					ret.Pattern = pattern
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// applyValidationBound sets the bound named key, one of min, max, len, gt, gte, lt or lte, on o.
+func applyValidationBound(o *options, t reflect.Type, key string, value string) {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return
+		}
+		switch key {
+		case "gt":
+			p++
+		case "lt":
+			p--
+		}
+		isMin := key == "min" || key == "len" || key == "gt" || key == "gte"
+		isMax := key == "max" || key == "len" || key == "lt" || key == "lte"
+		if t.Kind() == reflect.String {
+			if isMin {
+				o.MinLength = p
+			}
+			if isMax {
+				o.MaxLength = p
+			}
+			return
+		}
+		if isMin {
+			o.MinItems = p
+		}
+		if isMax {
+			o.MaxItems = p
+		}
+	default:
+		if !isNumber(t) {
+			return
+		}
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return
+		}
+		switch key {
+		case "min", "gte":
+			o.Minimum = &p
+		case "gt":
+			o.Minimum = &p
+			o.ExclusiveMinimum = true
+		case "max", "lte":
+			o.Maximum = &p
+		case "lt":
+			o.Maximum = &p
+			o.ExclusiveMaximum = true
+		case "len":
+			o.Minimum = &p
+			o.Maximum = &p
+		}
+	}
+}
+
+// mergeOptions returns the options of the swagger tag, taking precedence, merged over the validation options.
+func mergeOptions(validation *options, swagger *options) *options {
+	if validation == nil {
+		return swagger
+	}
+	if swagger == nil {
+		return validation
+	}
+
+	ret := *swagger
+	if !ret.RequiredSet {
+		ret.Required = validation.Required
+		ret.RequiredSet = validation.RequiredSet
+	}
+	if ret.Enum == nil {
+		ret.Enum = validation.Enum
+	}
+	if ret.Format == "" {
+		ret.Format = validation.Format
+	}
+	if ret.Maximum == nil {
+		ret.Maximum = validation.Maximum
+		ret.ExclusiveMaximum = validation.ExclusiveMaximum
+	}
+	if ret.Minimum == nil {
+		ret.Minimum = validation.Minimum
+		ret.ExclusiveMinimum = validation.ExclusiveMinimum
+	}
+	if ret.MaxLength == 0 {
+		ret.MaxLength = validation.MaxLength
+	}
+	if ret.MinLength == 0 {
+		ret.MinLength = validation.MinLength
+	}
+	if ret.Pattern == "" {
+		ret.Pattern = validation.Pattern
+	}
+	if ret.MaxItems == 0 {
+		ret.MaxItems = validation.MaxItems
+	}
+	if ret.MinItems == 0 {
+		ret.MinItems = validation.MinItems
+	}
+	if !ret.UniqueItems {
+		ret.UniqueItems = validation.UniqueItems
+	}
+	return &ret
+}